@@ -15,6 +15,48 @@ type ServerConfig struct {
 	JwtVerificationKeyPath string `json:"jwt_verification_key_path"`
 	Store                  string
 	Database               DBConfig
+	Generators             GeneratorsConfig
+	CRL                    CRLConfig
+	OCSP                   OCSPConfig
+	Renewal                RenewalConfig
+}
+
+type GeneratorsConfig struct {
+	DHParamsTimeout         int                                `json:"dh_params_timeout"`
+	CRLDistributionPointURL string                             `json:"crl_distribution_point_url"`
+	OCSPServerURL           string                             `json:"ocsp_server_url"`
+	External                map[string]ExternalGeneratorConfig `json:"external"`
+}
+
+// BuiltInValueTypes lists the value types generated by the config server
+// itself, which external generators cannot replace.
+var BuiltInValueTypes = []string{
+	"password", "ssh", "rsa", "certificate", "user", "symmetric_key", "uuid",
+	"jwt_signing_key", "wireguard", "totp", "dh_params", "ssh_certificate",
+}
+
+type ExternalGeneratorConfig struct {
+	Path             string   `json:"path"`
+	Args             []string `json:"args"`
+	Timeout          int      `json:"timeout"`
+	SecretParameters []string `json:"secret_parameters"`
+}
+
+type CRLConfig struct {
+	ValidityHours int `json:"validity_hours"`
+}
+
+type OCSPConfig struct {
+	Path          string            `json:"path"`
+	ValidityHours int               `json:"validity_hours"`
+	Responders    map[string]string `json:"responders"`
+}
+
+type RenewalConfig struct {
+	Prefixes        []string `json:"prefixes"`
+	IntervalMinutes int      `json:"interval_minutes"`
+	WindowDays      int      `json:"window_days"`
+	DryRun          bool     `json:"dry_run"`
 }
 
 type DBConnectionConfig struct {
@@ -49,6 +91,18 @@ func ParseConfig(filename string) (ServerConfig, error) {
 		return config, errors.Error("Certificate file path and key file path should be defined")
 	}
 
+	for valueType, external := range config.Generators.External {
+		for _, builtInType := range BuiltInValueTypes {
+			if valueType == builtInType {
+				return config, errors.Errorf("External generator '%s' cannot replace a built-in type", valueType)
+			}
+		}
+
+		if external.Path == "" {
+			return config, errors.Errorf("External generator '%s' must define a path", valueType)
+		}
+	}
+
 	if (&config.Database != nil) && (&config.Database.Adapter != nil) { //nolint:staticcheck
 		config.Database.Adapter = strings.ToLower(config.Database.Adapter)
 	}
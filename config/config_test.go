@@ -79,6 +79,152 @@ var _ = Describe("ParseConfig", func() {
 				Expect(serverConfig.Database.ConnectionOptions.MaxOpenConnections).To(Equal(12))
 				Expect(serverConfig.Database.ConnectionOptions.MaxIdleConnections).To(Equal(25))
 			})
+
+			It("should parse generators settings", func() {
+				configFile.WriteString( //nolint:errcheck
+					`
+{
+   "port":9000,
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "generators":{
+      "dh_params_timeout":45,
+      "crl_distribution_point_url":"https://config-server:8080/v1/crl",
+      "ocsp_server_url":"https://config-server:8080/v1/ocsp"
+   }
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.Generators.DHParamsTimeout).To(Equal(45))
+				Expect(serverConfig.Generators.CRLDistributionPointURL).To(Equal("https://config-server:8080/v1/crl"))
+				Expect(serverConfig.Generators.OCSPServerURL).To(Equal("https://config-server:8080/v1/ocsp"))
+			})
+
+			It("should parse OCSP settings", func() {
+				configFile.WriteString( //nolint:errcheck
+					`
+{
+   "port":9000,
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ocsp":{
+      "path":"/ocsp",
+      "validity_hours":12,
+      "responders":{
+         "my-ca":"my-ocsp-responder"
+      }
+   }
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.OCSP.Path).To(Equal("/ocsp"))
+				Expect(serverConfig.OCSP.ValidityHours).To(Equal(12))
+				Expect(serverConfig.OCSP.Responders).To(Equal(map[string]string{"my-ca": "my-ocsp-responder"}))
+			})
+
+			It("should parse external generators", func() {
+				configFile.WriteString( //nolint:errcheck
+					`
+{
+   "port":9000,
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "generators":{
+      "external":{
+         "vault_token":{
+            "path":"/var/vcap/packages/vault-token/bin/generate",
+            "args":["--ttl","1h"],
+            "timeout":10,
+            "secret_parameters":["root_token"]
+         }
+      }
+   }
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.Generators.External).To(Equal(map[string]ExternalGeneratorConfig{
+					"vault_token": {Path: "/var/vcap/packages/vault-token/bin/generate", Args: []string{"--ttl", "1h"}, Timeout: 10, SecretParameters: []string{"root_token"}},
+				}))
+			})
+
+			It("should return an error for external generators without a path", func() {
+				configFile.WriteString( //nolint:errcheck
+					`
+{
+   "port":9000,
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "generators":{
+      "external":{
+         "vault_token":{}
+      }
+   }
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).To(MatchError("External generator 'vault_token' must define a path"))
+			})
+
+			It("should return an error for external generators named after a built-in type", func() {
+				configFile.WriteString( //nolint:errcheck
+					`
+{
+   "port":9000,
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "generators":{
+      "external":{
+         "password":{"path":"/var/vcap/packages/password/bin/generate"}
+      }
+   }
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).To(MatchError("External generator 'password' cannot replace a built-in type"))
+			})
+
+			It("should parse renewal settings", func() {
+				configFile.WriteString( //nolint:errcheck
+					`
+{
+   "port":9000,
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "renewal":{
+      "prefixes":["/my-director/"],
+      "interval_minutes":15,
+      "window_days":14,
+      "dry_run":true
+   }
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.Renewal.Prefixes).To(Equal([]string{"/my-director/"}))
+				Expect(serverConfig.Renewal.IntervalMinutes).To(Equal(15))
+				Expect(serverConfig.Renewal.WindowDays).To(Equal(14))
+				Expect(serverConfig.Renewal.DryRun).To(BeTrue())
+			})
+
+			It("should parse CRL settings", func() {
+				configFile.WriteString( //nolint:errcheck
+					`
+{
+   "port":9000,
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "crl":{
+      "validity_hours":72
+   }
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.CRL.ValidityHours).To(Equal(72))
+			})
 		})
 
 		Context("has missing keys", func() {
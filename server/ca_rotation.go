@@ -0,0 +1,53 @@
+package server
+
+import (
+	"github.com/shono09835/config-server/store"
+)
+
+// A CA is rotated in three steps. 'transition' stores a new version with the
+// phase 'transitional': it is trusted but the previous version keeps signing.
+// 'promote' moves the new version to 'promoted' so that it signs while the
+// previous version is still trusted. 'finish' clears the phase, dropping the
+// previous version.
+const (
+	RotationPhaseTransitional = "transitional"
+	RotationPhasePromoted     = "promoted"
+)
+
+// signingVersion returns the version of a CA that signs certificates, given
+// all of its versions ordered latest first.
+func signingVersion(versions store.Configurations) store.Configuration {
+	if versions[0].RotationPhase == RotationPhaseTransitional && len(versions) > 1 {
+		return versions[1]
+	}
+	return versions[0]
+}
+
+// signingOrder returns the versions of a name ordered latest first, except
+// that the signing version of a CA in transition comes before the new one,
+// so that clients taking the first version keep getting the signing CA.
+func signingOrder(versions store.Configurations) store.Configurations {
+	if len(versions) < 2 || versions[0].RotationPhase != RotationPhaseTransitional {
+		return versions
+	}
+
+	ordered := store.Configurations{versions[1], versions[0]}
+	return append(ordered, versions[2:]...)
+}
+
+// trustedVersions returns the versions of a CA that certificates signed by it
+// should trust, starting with the signing version.
+func trustedVersions(versions store.Configurations) store.Configurations {
+	if len(versions) < 2 {
+		return versions[:1]
+	}
+
+	switch versions[0].RotationPhase {
+	case RotationPhaseTransitional:
+		return store.Configurations{versions[1], versions[0]}
+	case RotationPhasePromoted:
+		return store.Configurations{versions[0], versions[1]}
+	default:
+		return versions[:1]
+	}
+}
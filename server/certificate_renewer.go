@@ -0,0 +1,288 @@
+package server
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/config"
+	"github.com/shono09835/config-server/log"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+const (
+	defaultRenewalInterval = time.Hour
+	defaultRenewalWindow   = 30 * 24 * time.Hour
+)
+
+// CertificateRenewer regenerates certificates that expire soon. Only names
+// starting with one of the configured prefixes are renewed, and only if they
+// were generated by the config server.
+type CertificateRenewer struct {
+	store                 store.Store
+	valueGeneratorFactory types.ValueGeneratorFactory
+	prefixes              []string
+	interval              time.Duration
+	window                time.Duration
+	dryRun                bool
+}
+
+type renewalCandidate struct {
+	configuration store.Configuration
+	certificate   *x509.Certificate
+}
+
+func NewCertificateRenewer(store store.Store, valueGeneratorFactory types.ValueGeneratorFactory, config config.RenewalConfig) (CertificateRenewer, error) {
+	if store == nil {
+		return CertificateRenewer{}, errors.Error("Data store must be set")
+	}
+
+	interval := time.Duration(config.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = defaultRenewalInterval
+	}
+
+	window := time.Duration(config.WindowDays) * 24 * time.Hour
+	if window <= 0 {
+		window = defaultRenewalWindow
+	}
+
+	return CertificateRenewer{
+		store:                 store,
+		valueGeneratorFactory: valueGeneratorFactory,
+		prefixes:              config.Prefixes,
+		interval:              interval,
+		window:                window,
+		dryRun:                config.DryRun,
+	}, nil
+}
+
+// Start renews expiring certificates in the background every interval. It
+// does nothing when no prefix has opted in to renewal.
+func (r CertificateRenewer) Start() {
+	if len(r.prefixes) == 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := r.RenewExpiring(time.Now()); err != nil {
+				log.Logger.Error("CertificateRenewer", "Renewing certificates: %s", err.Error())
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// RenewExpiring renews every certificate that expires within the window
+// after now, and returns the renewals it recorded. In dry-run mode the
+// renewals are only recorded, once for each version. CAs are renewed before the certificates they
+// issue, so that those are signed by the renewed CA.
+func (r CertificateRenewer) RenewExpiring(now time.Time) (store.Renewals, error) {
+	candidates, err := r.findExpiring(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var renewals store.Renewals
+	var renewErrors []error
+
+	for _, candidate := range candidates {
+		if r.dryRun {
+			recorded, err := r.isRecorded(candidate)
+			if err != nil {
+				renewErrors = append(renewErrors, errors.WrapErrorf(err, "Renewing '%s'", candidate.configuration.Name))
+				continue
+			}
+			if recorded {
+				continue
+			}
+		}
+
+		renewal, err := r.renew(candidate, now)
+		if err != nil {
+			renewErrors = append(renewErrors, errors.WrapErrorf(err, "Renewing '%s'", candidate.configuration.Name))
+			continue
+		}
+		renewals = append(renewals, renewal)
+	}
+
+	if len(renewErrors) != 0 {
+		return renewals, errors.NewMultiError(renewErrors...)
+	}
+
+	return renewals, nil
+}
+
+func (r CertificateRenewer) findExpiring(now time.Time) ([]renewalCandidate, error) {
+	seen := map[string]bool{}
+	var candidates []renewalCandidate
+
+	for _, prefix := range r.prefixes {
+		configurations, err := r.store.GetLatestByNamePrefix(prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, configuration := range configurations {
+			if seen[configuration.Name] {
+				continue
+			}
+			seen[configuration.Name] = true
+
+			// Values set with PUT have no checksum, and CAs being rotated
+			// are managed through the rotate endpoint.
+			if configuration.ParameterChecksum == "" || configuration.RotationPhase != "" {
+				continue
+			}
+
+			certificate, err := parseStoredCertificate(configuration)
+			if err != nil || certificate.NotAfter.After(now.Add(r.renewalWindow(certificate))) {
+				continue
+			}
+
+			candidates = append(candidates, renewalCandidate{configuration: configuration, certificate: certificate})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].certificate.IsCA && !candidates[j].certificate.IsCA
+	})
+
+	return candidates, nil
+}
+
+// renewalWindow returns how long before expiry the certificate is renewed.
+// Certificates whose whole lifetime fits in the window would be renewed again
+// on every pass, so they are renewed once a third of their lifetime remains.
+func (r CertificateRenewer) renewalWindow(certificate *x509.Certificate) time.Duration {
+	lifetime := certificate.NotAfter.Sub(certificate.NotBefore)
+	if lifetime <= r.window {
+		return lifetime / 3
+	}
+	return r.window
+}
+
+func (r CertificateRenewer) renew(candidate renewalCandidate, now time.Time) (store.Renewal, error) {
+	renewal := store.Renewal{
+		Name:       candidate.configuration.Name,
+		PreviousID: candidate.configuration.ID,
+		ExpiresAt:  candidate.certificate.NotAfter,
+		RenewedAt:  now,
+		DryRun:     r.dryRun,
+	}
+
+	parameters, caName, err := r.renewalParameters(candidate)
+	if err != nil {
+		return renewal, err
+	}
+
+	if !r.dryRun {
+		generator, err := r.valueGeneratorFactory.GetGenerator("certificate")
+		if err != nil {
+			return renewal, err
+		}
+
+		value, err := generateValue(generator, candidate.configuration.Name, parameters, store.Configurations{candidate.configuration})
+		if err != nil {
+			return renewal, err
+		}
+
+		normalizedParameters, err := types.NormalizeParameters(generator, parameters)
+		if err != nil {
+			return renewal, err
+		}
+
+		// The previous checksum is kept so that converging with the original
+		// parameters does not regenerate the renewed certificate.
+		configuration, err := saveGenerated(r.store, store.Configuration{
+			Name:              candidate.configuration.Name,
+			ParameterChecksum: candidate.configuration.ParameterChecksum,
+			GeneratorType:     "certificate",
+			Parameters:        normalizedParameters,
+		}, value, caName)
+		if err != nil {
+			return renewal, err
+		}
+		renewal.ConfigurationID = configuration.ID
+	}
+
+	return renewal, r.store.PutRenewal(renewal)
+}
+
+// isRecorded tells whether a dry-run renewal of the candidate's version has
+// already been recorded.
+func (r CertificateRenewer) isRecorded(candidate renewalCandidate) (bool, error) {
+	renewals, err := r.store.GetRenewals(candidate.configuration.Name)
+	if err != nil {
+		return false, err
+	}
+
+	for _, renewal := range renewals {
+		if renewal.DryRun && renewal.PreviousID == candidate.configuration.ID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// renewalParameters returns the parameters to renew the certificate with,
+// and the name of the CA that signs it. Certificates generated before their
+// parameters were stored are renewed with parameters read from the
+// certificate itself. CAs always keep their private key, so that the
+// certificates they issued stay valid; changing the key of a CA is left to
+// the rotate endpoint.
+func (r CertificateRenewer) renewalParameters(candidate renewalCandidate) (map[string]interface{}, string, error) {
+	var parameters map[string]interface{}
+	var caName string
+
+	if candidate.configuration.GeneratorType == "certificate" && candidate.configuration.Parameters != "" {
+		err := json.Unmarshal([]byte(candidate.configuration.Parameters), &parameters)
+		if err != nil {
+			return nil, "", errors.WrapError(err, "Failed to parse stored parameters")
+		}
+
+		caName = issuingCAName("certificate", parameters)
+	} else {
+		var err error
+		caName, err = r.issuingCA(candidate.certificate)
+		if err != nil {
+			return nil, "", err
+		}
+
+		parameters = types.CertificateParameters(candidate.certificate, caName)
+	}
+
+	if candidate.certificate.IsCA {
+		parameters["reuse_key"] = true
+	}
+
+	return parameters, caName, nil
+}
+
+// issuingCA returns the name of the CA that issued the certificate, or an
+// empty string for self-signed certificates.
+func (r CertificateRenewer) issuingCA(certificate *x509.Certificate) (string, error) {
+	if bytes.Equal(certificate.RawIssuer, certificate.RawSubject) && certificate.CheckSignatureFrom(certificate) == nil {
+		return "", nil
+	}
+
+	issuedCertificate, err := r.store.GetIssuedCertificate(formatSerialNumber(certificate.SerialNumber))
+	if err != nil {
+		return "", err
+	}
+
+	if issuedCertificate.CAName == "" {
+		return "", errors.Error("Issuing CA is unknown")
+	}
+
+	return issuedCertificate.CAName, nil
+}
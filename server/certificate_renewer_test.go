@@ -0,0 +1,278 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/shono09835/config-server/config"
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("CertificateRenewer", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewCertificateRenewer(nil, nil, config.RenewalConfig{})
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a renewer with store", func() {
+		var (
+			memoryStore           store.Store
+			valueGeneratorFactory types.ValueGeneratorFactory
+			renewalConfig         config.RenewalConfig
+		)
+
+		generate := func(name string, parameters map[string]interface{}) {
+			requestHandler, _ := NewRequestHandler(memoryStore, valueGeneratorFactory)
+
+			body, _ := json.Marshal(map[string]interface{}{"name": name, "type": "certificate", "parameters": parameters})
+			req, _ := http.NewRequest("POST", "/v1/data", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			recorder := httptest.NewRecorder()
+			requestHandler.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		}
+
+		latest := func(name string) store.Configuration {
+			configurations, err := memoryStore.GetByName(name)
+			Expect(err).ToNot(HaveOccurred())
+			return configurations[0]
+		}
+
+		renewExpiringAt := func(now time.Time) (store.Renewals, error) {
+			renewer, err := NewCertificateRenewer(memoryStore, valueGeneratorFactory, renewalConfig)
+			Expect(err).ToNot(HaveOccurred())
+			return renewer.RenewExpiring(now)
+		}
+
+		// Eight days from now, less than a third of the lifetime of the 10 day
+		// certificates remains.
+		renewExpiring := func() (store.Renewals, error) {
+			return renewExpiringAt(time.Now().Add(8 * 24 * time.Hour))
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			loader := NewX509Loader(memoryStore)
+			valueGeneratorFactory = types.NewValueGeneratorConcrete(loader, NewSSHKeyLoader(memoryStore), config.GeneratorsConfig{})
+			renewalConfig = config.RenewalConfig{Prefixes: []string{"/renewed/"}}
+
+			generate("/renewed/ca", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			generate("/renewed/expiring", map[string]interface{}{"ca": "/renewed/ca", "common_name": "expiring", "alternative_names": []string{"expiring.example.com"}, "duration": 10})
+			generate("/renewed/valid", map[string]interface{}{"ca": "/renewed/ca", "common_name": "valid", "duration": 365})
+			generate("/ignored/expiring", map[string]interface{}{"ca": "/renewed/ca", "common_name": "ignored", "duration": 10})
+		})
+
+		It("renews certificates expiring within the window under the opted in prefixes", func() {
+			previous := latest("/renewed/expiring")
+
+			renewals, err := renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(HaveLen(1))
+
+			renewed := latest("/renewed/expiring")
+			Expect(renewed.ID).ToNot(Equal(previous.ID))
+			Expect(renewed.ParameterChecksum).To(Equal(previous.ParameterChecksum))
+			Expect(renewed.GeneratorType).To(Equal("certificate"))
+			Expect(renewed.Parameters).To(Equal(previous.Parameters))
+
+			Expect(renewals[0].Name).To(Equal("/renewed/expiring"))
+			Expect(renewals[0].PreviousID).To(Equal(previous.ID))
+			Expect(renewals[0].ConfigurationID).To(Equal(renewed.ID))
+			Expect(renewals[0].DryRun).To(BeFalse())
+
+			previousCertificate := parseStoredCertificateValue(previous.Value)
+			renewedCertificate := parseStoredCertificateValue(renewed.Value)
+			Expect(renewedCertificate.SerialNumber).ToNot(Equal(previousCertificate.SerialNumber))
+			Expect(renewedCertificate.Subject.String()).To(Equal(previousCertificate.Subject.String()))
+			Expect(renewedCertificate.DNSNames).To(Equal([]string{"expiring.example.com"}))
+			Expect(renewedCertificate.NotAfter.Sub(renewedCertificate.NotBefore)).To(Equal(10 * 24 * time.Hour))
+			Expect(renewedCertificate.CheckSignatureFrom(parseStoredCertificateValue(latest("/renewed/ca").Value))).To(Succeed())
+
+			recorded, err := memoryStore.GetRenewals("/renewed/expiring")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recorded).To(Equal(renewals))
+
+			issuedCertificate, err := memoryStore.GetIssuedCertificate(renewedCertificate.SerialNumber.Text(16))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issuedCertificate.CAName).To(Equal("/renewed/ca"))
+		})
+
+		It("does not renew certificates outside of the opted in prefixes", func() {
+			previous := latest("/ignored/expiring")
+
+			_, err := renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(latest("/ignored/expiring").ID).To(Equal(previous.ID))
+		})
+
+		It("honours the configured window", func() {
+			renewalConfig.WindowDays = 360
+
+			renewals, err := renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(HaveLen(2))
+		})
+
+		It("only records renewals in dry-run mode", func() {
+			renewalConfig.DryRun = true
+			previous := latest("/renewed/expiring")
+
+			renewals, err := renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(HaveLen(1))
+			Expect(renewals[0].DryRun).To(BeTrue())
+			Expect(renewals[0].ConfigurationID).To(BeEmpty())
+
+			Expect(latest("/renewed/expiring").ID).To(Equal(previous.ID))
+
+			recorded, err := memoryStore.GetRenewals("/renewed/expiring")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recorded).To(Equal(renewals))
+		})
+
+		It("records a dry-run renewal only once for each version", func() {
+			renewalConfig.DryRun = true
+
+			renewals, err := renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(HaveLen(1))
+
+			renewals, err = renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(BeEmpty())
+
+			recorded, err := memoryStore.GetRenewals("/renewed/expiring")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recorded).To(HaveLen(1))
+		})
+
+		It("renews CAs before the certificates they issue", func() {
+			generate("/renewed/short-ca", map[string]interface{}{"is_ca": true, "common_name": "short-ca", "duration": 35})
+			generate("/renewed/a-leaf", map[string]interface{}{"ca": "/renewed/short-ca", "common_name": "leaf", "duration": 5})
+
+			renewals, err := renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(HaveLen(3))
+			Expect(renewals[0].Name).To(Equal("/renewed/short-ca"))
+
+			renewedCA := parseStoredCertificateValue(latest("/renewed/short-ca").Value)
+			Expect(renewedCA.IsCA).To(BeTrue())
+
+			renewedLeaf := parseStoredCertificateValue(latest("/renewed/a-leaf").Value)
+			Expect(renewedLeaf.CheckSignatureFrom(renewedCA)).To(Succeed())
+		})
+
+		It("keeps the private key of renewed CAs so that certificates they issued stay valid", func() {
+			generate("/renewed/short-ca", map[string]interface{}{"is_ca": true, "common_name": "short-ca", "duration": 35})
+			generate("/renewed/b-leaf", map[string]interface{}{"ca": "/renewed/short-ca", "common_name": "leaf", "duration": 365})
+			previousCA := parseStoredCertificateValue(latest("/renewed/short-ca").Value)
+			leaf := parseStoredCertificateValue(latest("/renewed/b-leaf").Value)
+
+			_, err := renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+
+			renewedCA := parseStoredCertificateValue(latest("/renewed/short-ca").Value)
+			Expect(renewedCA.SerialNumber).ToNot(Equal(previousCA.SerialNumber))
+			Expect(renewedCA.PublicKey).To(Equal(previousCA.PublicKey))
+			Expect(leaf.CheckSignatureFrom(renewedCA)).To(Succeed())
+
+			var parameters map[string]interface{}
+			Expect(json.Unmarshal([]byte(latest("/renewed/short-ca").Parameters), &parameters)).To(Succeed())
+			Expect(parameters).To(HaveKeyWithValue("reuse_key", true))
+		})
+
+		It("does not renew values that were not generated", func() {
+			value := latest("/renewed/expiring").Value
+			_, err := memoryStore.Put("/renewed/imported", value, "")
+			Expect(err).ToNot(HaveOccurred())
+
+			renewals, err := renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(HaveLen(1))
+			Expect(renewals[0].Name).To(Equal("/renewed/expiring"))
+		})
+
+		It("renews certificates with their stored parameters", func() {
+			generate("/renewed/pinned", map[string]interface{}{"ca": "/renewed/ca", "common_name": "pinned", "duration": 10, "reuse_key": true})
+			previous := latest("/renewed/pinned")
+
+			_, err := renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+
+			renewed := latest("/renewed/pinned")
+			Expect(renewed.ID).ToNot(Equal(previous.ID))
+
+			var previousValue, renewedValue struct {
+				Value types.CertResponse `json:"value"`
+			}
+			Expect(json.Unmarshal([]byte(previous.Value), &previousValue)).To(Succeed())
+			Expect(json.Unmarshal([]byte(renewed.Value), &renewedValue)).To(Succeed())
+			Expect(renewedValue.Value.PrivateKey).To(Equal(previousValue.Value.PrivateKey))
+			Expect(renewedValue.Value.Certificate).ToNot(Equal(previousValue.Value.Certificate))
+		})
+
+		It("records the parameters read from certificates stored without them", func() {
+			previous := latest("/renewed/expiring")
+			_, err := memoryStore.Put("/renewed/legacy", previous.Value, previous.ParameterChecksum)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = renewExpiring()
+			Expect(err).ToNot(HaveOccurred())
+
+			renewed := latest("/renewed/legacy")
+			Expect(renewed.GeneratorType).To(Equal("certificate"))
+
+			var parameters map[string]interface{}
+			Expect(json.Unmarshal([]byte(renewed.Parameters), &parameters)).To(Succeed())
+			Expect(parameters).To(HaveKeyWithValue("ca", "/renewed/ca"))
+			Expect(parameters).To(HaveKeyWithValue("common_name", "expiring"))
+			Expect(parameters).ToNot(HaveKey("reuse_key"))
+		})
+
+		It("renews certificates whose lifetime fits in the window only once", func() {
+			generate("/renewed/short", map[string]interface{}{"ca": "/renewed/ca", "common_name": "short", "duration": 5})
+
+			renewals, err := renewExpiringAt(time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(BeEmpty())
+
+			renewals, err = renewExpiringAt(time.Now().Add(4 * 24 * time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(HaveLen(1))
+			Expect(renewals[0].Name).To(Equal("/renewed/short"))
+			renewed := latest("/renewed/short")
+
+			renewals, err = renewExpiringAt(time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renewals).To(BeEmpty())
+			Expect(latest("/renewed/short").ID).To(Equal(renewed.ID))
+		})
+
+		It("returns an error for certificates with an unknown CA and renews the others", func() {
+			otherStore := store.NewMemoryStore()
+			putGeneratedCertificate(otherStore, "other-ca", map[string]interface{}{"is_ca": true, "common_name": "other-ca"})
+			foreign := putGeneratedCertificate(otherStore, "foreign", map[string]interface{}{"ca": "other-ca", "common_name": "foreign", "duration": 10})
+
+			value, _ := json.Marshal(map[string]interface{}{"value": foreign})
+			_, err := memoryStore.Put("/renewed/foreign", string(value), "some-checksum")
+			Expect(err).ToNot(HaveOccurred())
+
+			renewals, err := renewExpiring()
+			Expect(err).To(MatchError(ContainSubstring("Renewing '/renewed/foreign': Issuing CA is unknown")))
+			Expect(renewals).To(HaveLen(1))
+			Expect(renewals[0].Name).To(Equal("/renewed/expiring"))
+		})
+	})
+})
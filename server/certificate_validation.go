@@ -0,0 +1,184 @@
+package server
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// validateValue checks a value set through PUT against its declared type.
+// Values without a type are stored as given.
+func validateValue(valueType string, value interface{}) error {
+	switch valueType {
+	case "":
+		return nil
+	case "certificate":
+		return validateCertificateValue(value)
+	default:
+		return errors.Errorf("Unsupported value type '%s'", valueType)
+	}
+}
+
+// validateCertificateValue checks that a certificate value is usable by the
+// certificate loaders: the certificate is current, the private key belongs to
+// it and both 'ca' and 'chain' are its issuers.
+func validateCertificateValue(value interface{}) error {
+	certValue, ok := value.(map[string]interface{})
+	if !ok {
+		return errors.Error("Invalid certificate: value must be an object")
+	}
+
+	for _, key := range []string{"certificate", "private_key", "ca", "chain"} {
+		if _, isString := certValue[key].(string); certValue[key] != nil && !isString {
+			return errors.Errorf("Invalid certificate: '%s' must be of type string", key)
+		}
+	}
+
+	certPEM, _ := certValue["certificate"].(string)
+	if certPEM == "" {
+		return errors.Error("Invalid certificate: 'certificate' is required")
+	}
+
+	certificates, err := parseCertificatesPEM("certificate", certPEM)
+	if err != nil {
+		return err
+	}
+
+	if len(certificates) != 1 {
+		return errors.Error("Invalid certificate: 'certificate' must contain exactly one certificate")
+	}
+
+	certificate := certificates[0]
+
+	err = validateValidityPeriod("certificate", certificate)
+	if err != nil {
+		return err
+	}
+
+	if keyPEM, _ := certValue["private_key"].(string); keyPEM != "" {
+		err = validatePrivateKeyMatches(keyPEM, certificate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if caPEM, _ := certValue["ca"].(string); caPEM != "" {
+		caCertificates, err := parseCertificatesPEM("ca", caPEM)
+		if err != nil {
+			return err
+		}
+
+		err = validateValidityPeriod("ca", caCertificates[0])
+		if err != nil {
+			return err
+		}
+
+		if !isIssuedBy(certificate, caCertificates[0]) {
+			return errors.Error("Invalid certificate: 'certificate' is not signed by 'ca'")
+		}
+	}
+
+	if chainPEM, _ := certValue["chain"].(string); chainPEM != "" {
+		chain, err := parseCertificatesPEM("chain", chainPEM)
+		if err != nil {
+			return err
+		}
+
+		// The chain of a self-signed CA starts with the CA itself.
+		if chain[0].Equal(certificate) {
+			chain = chain[1:]
+		}
+
+		issued := certificate
+		for i, issuer := range chain {
+			if !isIssuedBy(issued, issuer) {
+				return errors.Errorf("Invalid certificate: 'chain' is broken at position %d, '%s' is not signed by '%s'", i, issued.Subject, issuer.Subject)
+			}
+			issued = issuer
+		}
+	}
+
+	return nil
+}
+
+func parseCertificatesPEM(key string, data string) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			return nil, errors.Errorf("Invalid certificate: '%s' contains an unexpected '%s' PEM block", key, block.Type)
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.WrapErrorf(err, "Invalid certificate: '%s' cannot be parsed", key)
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.Errorf("Invalid certificate: '%s' is not PEM encoded", key)
+	}
+
+	return certificates, nil
+}
+
+func validateValidityPeriod(key string, certificate *x509.Certificate) error {
+	now := time.Now()
+
+	if now.After(certificate.NotAfter) {
+		return errors.Errorf("Invalid certificate: '%s' expired at %s", key, certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	if now.Before(certificate.NotBefore) {
+		return errors.Errorf("Invalid certificate: '%s' is not valid before %s", key, certificate.NotBefore.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// validatePrivateKeyMatches checks the private key belongs to the
+// certificate. The certificate loaders only read PKCS#1 RSA keys, so other
+// keys are rejected rather than failing once the value is used as a CA.
+func validatePrivateKeyMatches(keyPEM string, certificate *x509.Certificate) error {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return errors.Error("Invalid certificate: 'private_key' is not PEM encoded")
+	}
+
+	if block.Type != "RSA PRIVATE KEY" {
+		return errors.Errorf("Invalid certificate: 'private_key' must be a PKCS#1 RSA key ('RSA PRIVATE KEY'), got '%s'", block.Type)
+	}
+
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return errors.WrapError(err, "Invalid certificate: 'private_key' cannot be parsed")
+	}
+
+	if !privateKey.PublicKey.Equal(certificate.PublicKey) {
+		return errors.Error("Invalid certificate: 'private_key' does not match 'certificate'")
+	}
+
+	return nil
+}
+
+// isIssuedBy reports whether the certificate's signature verifies against
+// the issuer. Self-signed certificates are accepted as their own issuer even
+// when they are not CAs.
+func isIssuedBy(certificate *x509.Certificate, issuer *x509.Certificate) bool {
+	if certificate.Equal(issuer) {
+		return issuer.CheckSignature(certificate.SignatureAlgorithm, certificate.RawTBSCertificate, certificate.Signature) == nil
+	}
+
+	return certificate.CheckSignatureFrom(issuer) == nil
+}
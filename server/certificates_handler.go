@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/store"
+)
+
+type certificatesHandler struct {
+	store store.Store
+}
+
+type certificateSummary struct {
+	Name         string `json:"name"`
+	ID           string `json:"id"`
+	Subject      string `json:"subject"`
+	Issuer       string `json:"issuer"`
+	SerialNumber string `json:"serial_number"`
+	NotAfter     string `json:"not_after"`
+	IsCA         bool   `json:"is_ca"`
+}
+
+func NewCertificatesHandler(store store.Store) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+	return certificatesHandler{store: store}, nil
+}
+
+func (handler certificatesHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	namePrefix := req.URL.Query().Get("name_prefix")
+	if namePrefix != "" {
+		if isNameValid, nameError := isValidName(namePrefix); !isNameValid {
+			http.Error(resWriter, NewErrorResponse(nameError).GenerateErrorMsg(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var expiresBefore time.Time
+	if expiresWithin := req.URL.Query().Get("expires_within"); expiresWithin != "" {
+		window, err := parseExpiryWindow(expiresWithin)
+		if err != nil {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+			return
+		}
+		expiresBefore = time.Now().Add(window)
+	}
+
+	configurations, err := handler.store.GetLatestByNamePrefix(namePrefix)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	summaries := []certificateSummary{}
+	for _, configuration := range configurations {
+		certificate, err := parseStoredCertificate(configuration)
+		if err != nil {
+			continue
+		}
+
+		if !expiresBefore.IsZero() && certificate.NotAfter.After(expiresBefore) {
+			continue
+		}
+
+		summaries = append(summaries, certificateSummary{
+			Name:         configuration.Name,
+			ID:           configuration.ID,
+			Subject:      certificate.Subject.String(),
+			Issuer:       certificate.Issuer.String(),
+			SerialNumber: formatSerialNumber(certificate.SerialNumber),
+			NotAfter:     certificate.NotAfter.UTC().Format(time.RFC3339),
+			IsCA:         certificate.IsCA,
+		})
+	}
+
+	result, err := json.Marshal(map[string]interface{}{"data": summaries})
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, string(result), http.StatusOK)
+}
+
+// parseExpiryWindow accepts a number of days such as '30d', or any duration
+// understood by time.ParseDuration such as '12h'.
+func parseExpiryWindow(value string) (time.Duration, error) {
+	var window time.Duration
+	var err error
+
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		var count int
+		count, err = strconv.Atoi(days)
+		window = time.Duration(count) * 24 * time.Hour
+	} else {
+		window, err = time.ParseDuration(value)
+	}
+
+	if err != nil || window < 0 {
+		return 0, errors.Errorf("Invalid expires_within '%s', expected a duration such as '30d' or '12h'", value)
+	}
+
+	return window, nil
+}
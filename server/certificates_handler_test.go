@@ -0,0 +1,126 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	. "github.com/shono09835/config-server/store/storefakes"
+)
+
+var _ = Describe("CertificatesHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewCertificatesHandler(nil)
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var (
+			handler     http.Handler
+			memoryStore store.Store
+		)
+
+		listCertificates := func(query string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("GET", "/v1/certificates"+query, nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		names := func(recorder *httptest.ResponseRecorder) []string {
+			var response struct {
+				Data []map[string]interface{} `json:"data"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+
+			result := []string{}
+			for _, certificate := range response.Data {
+				result = append(result, certificate["name"].(string))
+			}
+			return result
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			handler, _ = NewCertificatesHandler(memoryStore)
+
+			putGeneratedCertificate(memoryStore, "/team/ca", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			putGeneratedCertificate(memoryStore, "/team/short", map[string]interface{}{"ca": "/team/ca", "common_name": "short", "duration": 10})
+			putGeneratedCertificate(memoryStore, "/other/short", map[string]interface{}{"ca": "/team/ca", "common_name": "other", "duration": 10})
+			_, err := memoryStore.Put("/team/password", `{"value":"secret"}`, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return 405 Method Not Allowed for anything but GET", func() {
+			req, _ := http.NewRequest("POST", "/v1/certificates", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should return 400 Bad Request for invalid expiry windows", func() {
+			recorder := listCertificates("?expires_within=soon")
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Invalid expires_within 'soon'"))
+		})
+
+		It("should return 400 Bad Request for invalid name prefixes", func() {
+			recorder := listCertificates("?name_prefix=a%20b")
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("lists the latest version of every certificate", func() {
+			recorder := listCertificates("")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(names(recorder)).To(Equal([]string{"/other/short", "/team/ca", "/team/short"}))
+		})
+
+		It("describes each certificate", func() {
+			recorder := listCertificates("?name_prefix=/team/ca")
+
+			var response struct {
+				Data []map[string]interface{} `json:"data"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Data).To(HaveLen(1))
+
+			certificate := response.Data[0]
+			Expect(certificate["id"]).To(Equal("0"))
+			Expect(certificate["subject"]).To(ContainSubstring("CN=my-ca"))
+			Expect(certificate["issuer"]).To(ContainSubstring("CN=my-ca"))
+			Expect(certificate["serial_number"]).ToNot(BeEmpty())
+			Expect(certificate["not_after"]).ToNot(BeEmpty())
+			Expect(certificate["is_ca"]).To(BeTrue())
+		})
+
+		It("filters certificates expiring within the given window", func() {
+			Expect(names(listCertificates("?expires_within=30d"))).To(Equal([]string{"/other/short", "/team/short"}))
+			Expect(names(listCertificates("?expires_within=48h"))).To(BeEmpty())
+		})
+
+		It("filters certificates by name prefix", func() {
+			Expect(names(listCertificates("?name_prefix=/team/&expires_within=30d"))).To(Equal([]string{"/team/short"}))
+		})
+
+		It("should return 500 when the store fails", func() {
+			fakeStore := &FakeStore{}
+			fakeStore.GetLatestByNamePrefixReturns(nil, errors.New("fake-error"))
+			handler, _ = NewCertificatesHandler(fakeStore)
+
+			Expect(listCertificates("").Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
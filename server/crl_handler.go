@@ -0,0 +1,130 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/config"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+const defaultCRLValidity = 24 * time.Hour
+
+var oidExtensionReasonCode = asn1.ObjectIdentifier{2, 5, 29, 21}
+
+type crlHandler struct {
+	store    store.Store
+	loader   types.CertsLoader
+	validity time.Duration
+}
+
+func NewCRLHandler(store store.Store, loader types.CertsLoader, config config.CRLConfig) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+
+	validity := time.Duration(config.ValidityHours) * time.Hour
+	if validity <= 0 {
+		validity = defaultCRLValidity
+	}
+
+	return crlHandler{store: store, loader: loader, validity: validity}, nil
+}
+
+func (handler crlHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	caName := req.URL.Query().Get("ca")
+	if isNameValid, nameError := isValidName(caName); !isNameValid {
+		http.Error(resWriter, NewErrorResponse(nameError).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	format := req.URL.Query().Get("format")
+	if format != "" && format != "der" && format != "pem" {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Unsupported CRL format: %s", format)).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	caCert, caKey, err := handler.loader.LoadCerts(caName)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(errors.WrapError(err, "Loading CA")).GenerateErrorMsg(), http.StatusNotFound)
+		return
+	}
+
+	revocations, err := handler.store.GetRevocations(caName)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	var entries []pkix.RevokedCertificate
+	for _, revocation := range revocations {
+		serialNumber, err := parseSerialNumber(revocation.SerialNumber)
+		if err != nil {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+			return
+		}
+
+		entry, err := revokedCertificate(serialNumber, revocation)
+		if err != nil {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+			return
+		}
+
+		entries = append(entries, entry)
+	}
+
+	now := time.Now().UTC()
+
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		RevokedCertificates: entries,
+		Number:              big.NewInt(now.UnixNano()),
+		ThisUpdate:          now,
+		NextUpdate:          now.Add(handler.validity),
+	}, caCert, caKey)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(errors.WrapError(err, "Signing CRL")).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	if format == "pem" {
+		resWriter.Header().Set("Content-Type", "application/x-pem-file")
+		respond(resWriter, string(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl})), http.StatusOK)
+		return
+	}
+
+	resWriter.Header().Set("Content-Type", "application/pkix-crl")
+	respond(resWriter, string(crl), http.StatusOK)
+}
+
+// revokedCertificate builds a CRL entry, carrying the revocation reason as a
+// CRLReason extension unless it is unspecified.
+func revokedCertificate(serialNumber *big.Int, revocation store.Revocation) (pkix.RevokedCertificate, error) {
+	entry := pkix.RevokedCertificate{
+		SerialNumber:   serialNumber,
+		RevocationTime: revocation.RevokedAt,
+	}
+
+	if revocation.Reason != 0 {
+		reasonCode, err := asn1.Marshal(asn1.Enumerated(revocation.Reason))
+		if err != nil {
+			return pkix.RevokedCertificate{}, errors.WrapError(err, "Encoding revocation reason")
+		}
+
+		entry.Extensions = []pkix.Extension{{Id: oidExtensionReasonCode, Value: reasonCode}}
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,145 @@
+package server_test
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/shono09835/config-server/config"
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	. "github.com/shono09835/config-server/store/storefakes"
+)
+
+func parseStoredCertificateValue(value string) *x509.Certificate {
+	var container struct {
+		Value struct {
+			Certificate string `json:"certificate"`
+		} `json:"value"`
+	}
+	Expect(json.Unmarshal([]byte(value), &container)).To(Succeed())
+
+	block, _ := pem.Decode([]byte(container.Value.Certificate))
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	Expect(err).ToNot(HaveOccurred())
+
+	return certificate
+}
+
+var _ = Describe("CRLHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewCRLHandler(nil, nil, config.CRLConfig{})
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var (
+			handler     http.Handler
+			memoryStore store.Store
+			caCert      *x509.Certificate
+		)
+
+		getCRL := func(url string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("GET", url, nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			handler, _ = NewCRLHandler(memoryStore, NewX509Loader(memoryStore), config.CRLConfig{ValidityHours: 48})
+
+			putGeneratedCertificate(memoryStore, "my-ca", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			caCert, _, _ = NewX509Loader(memoryStore).LoadCerts("my-ca")
+
+			Expect(memoryStore.PutRevocation(store.Revocation{
+				CAName:       "my-ca",
+				SerialNumber: "abc",
+				Reason:       1,
+				RevokedAt:    time.Unix(1500000000, 0).UTC(),
+			})).To(Succeed())
+		})
+
+		It("should return 405 Method Not Allowed for anything but GET", func() {
+			req, _ := http.NewRequest("POST", "/v1/crl?ca=my-ca", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should return 400 Bad Request when the CA name is invalid", func() {
+			recorder := getCRL("/v1/crl?ca=my!ca")
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return 400 Bad Request for unsupported formats", func() {
+			recorder := getCRL("/v1/crl?ca=my-ca&format=xml")
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Unsupported CRL format: xml"))
+		})
+
+		It("should return 404 Not Found when the CA does not exist", func() {
+			recorder := getCRL("/v1/crl?ca=missing-ca")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return 500 Internal Server Error when the store errors", func() {
+			mockStore := &FakeStore{}
+			mockStore.GetRevocationsReturns(nil, errors.New("fake-error"))
+			handler, _ = NewCRLHandler(mockStore, NewX509Loader(memoryStore), config.CRLConfig{})
+
+			recorder := getCRL("/v1/crl?ca=my-ca")
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("returns a DER CRL signed by the CA listing the revocations", func() {
+			recorder := getCRL("/v1/crl?ca=my-ca")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/pkix-crl"))
+
+			crl, err := x509.ParseRevocationList(recorder.Body.Bytes())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(crl.CheckSignatureFrom(caCert)).To(Succeed())
+			Expect(crl.NextUpdate.Sub(crl.ThisUpdate)).To(Equal(48 * time.Hour))
+			Expect(crl.RevokedCertificates).To(HaveLen(1))
+			Expect(crl.RevokedCertificates[0].SerialNumber.Text(16)).To(Equal("abc"))
+			Expect(crl.RevokedCertificates[0].RevocationTime).To(Equal(time.Unix(1500000000, 0).UTC()))
+
+			Expect(crl.RevokedCertificates[0].Extensions).To(HaveLen(1))
+			Expect(crl.RevokedCertificates[0].Extensions[0].Id).To(Equal(asn1.ObjectIdentifier{2, 5, 29, 21}))
+			var reasonCode asn1.Enumerated
+			_, err = asn1.Unmarshal(crl.RevokedCertificates[0].Extensions[0].Value, &reasonCode)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reasonCode).To(Equal(asn1.Enumerated(1)))
+		})
+
+		It("returns a PEM CRL when requested", func() {
+			recorder := getCRL("/v1/crl?ca=my-ca&format=pem")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			block, _ := pem.Decode(recorder.Body.Bytes())
+			Expect(block.Type).To(Equal("X509 CRL"))
+
+			_, err := x509.ParseRevocationList(block.Bytes)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
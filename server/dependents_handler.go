@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/store"
+)
+
+type dependentsHandler struct {
+	store store.Store
+}
+
+type dependentSummary struct {
+	Name         string `json:"name"`
+	ID           string `json:"id"`
+	SerialNumber string `json:"serial_number"`
+	CAID         string `json:"ca_id"`
+	UpToDate     bool   `json:"up_to_date"`
+}
+
+func NewDependentsHandler(store store.Store) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+	return dependentsHandler{store: store}, nil
+}
+
+func (handler dependentsHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	caName := req.URL.Query().Get("name")
+	if isNameValid, nameError := isValidName(caName); !isNameValid {
+		http.Error(resWriter, NewErrorResponse(nameError).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	caConfigurationID, err := signingCAConfigurationID(handler.store, caName)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	if caConfigurationID == "" {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' not found", caName)).GenerateErrorMsg(), http.StatusNotFound)
+		return
+	}
+
+	issuedCertificates, err := handler.store.GetIssuedCertificates(caName)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	// Only the latest version of each name is a dependent; older versions
+	// have already been replaced.
+	latestIDs := map[string]string{}
+	dependents := []dependentSummary{}
+	for _, issuedCertificate := range issuedCertificates {
+		latestID, found := latestIDs[issuedCertificate.Name]
+		if !found {
+			values, err := handler.store.GetByName(issuedCertificate.Name)
+			if err != nil {
+				http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+				return
+			}
+			if len(values) != 0 {
+				latestID = values[0].ID
+			}
+			latestIDs[issuedCertificate.Name] = latestID
+		}
+
+		if issuedCertificate.ConfigurationID != latestID {
+			continue
+		}
+
+		dependents = append(dependents, dependentSummary{
+			Name:         issuedCertificate.Name,
+			ID:           issuedCertificate.ConfigurationID,
+			SerialNumber: issuedCertificate.SerialNumber,
+			CAID:         issuedCertificate.CAConfigurationID,
+			UpToDate:     issuedCertificate.CAConfigurationID == caConfigurationID,
+		})
+	}
+
+	sort.Slice(dependents, func(i, j int) bool {
+		return dependents[i].Name < dependents[j].Name
+	})
+
+	result, err := json.Marshal(map[string]interface{}{"data": dependents})
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, string(result), http.StatusOK)
+}
@@ -0,0 +1,138 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/shono09835/config-server/config"
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+	. "github.com/shono09835/config-server/types/typesfakes"
+)
+
+var _ = Describe("DependentsHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewDependentsHandler(nil)
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var (
+			handler        http.Handler
+			requestHandler http.Handler
+			memoryStore    store.Store
+		)
+
+		listDependents := func(query string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("GET", "/v1/data/dependents"+query, nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		generate := func(body string) {
+			req, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(body))
+			recorder := httptest.NewRecorder()
+			requestHandler.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		}
+
+		dependents := func(recorder *httptest.ResponseRecorder) []map[string]interface{} {
+			var response struct {
+				Data []map[string]interface{} `json:"data"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			return response.Data
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			handler, _ = NewDependentsHandler(memoryStore)
+			requestHandler, _ = NewRequestHandler(memoryStore, types.NewValueGeneratorConcrete(NewX509Loader(memoryStore), &FakeSSHKeysLoader{}, config.GeneratorsConfig{}))
+
+			putGeneratedCertificate(memoryStore, "my-ca", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			generate(`{"name":"server","type":"certificate","parameters":{"common_name":"server","ca":"my-ca"}}`)
+			generate(`{"name":"client","type":"certificate","parameters":{"common_name":"client","ca":"my-ca"}}`)
+		})
+
+		It("should return 405 Method Not Allowed for anything but GET", func() {
+			req, _ := http.NewRequest("POST", "/v1/data/dependents?name=my-ca", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should return 400 Bad Request for invalid names", func() {
+			recorder := listDependents("?name=a%20b")
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return 404 Not Found if the CA does not exist", func() {
+			recorder := listDependents("?name=missing-ca")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(ContainSubstring("Name 'missing-ca' not found"))
+		})
+
+		It("should list the certificates issued by the CA", func() {
+			recorder := listDependents("?name=my-ca")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			result := dependents(recorder)
+			Expect(result).To(HaveLen(2))
+
+			clientValues, _ := memoryStore.GetByName("client")
+			clientCertificate := parseStoredCertificateValue(clientValues[0].Value)
+
+			Expect(result[0]).To(Equal(map[string]interface{}{
+				"name":          "client",
+				"id":            clientValues[0].ID,
+				"serial_number": clientCertificate.SerialNumber.Text(16),
+				"ca_id":         "0",
+				"up_to_date":    true,
+			}))
+			Expect(result[1]["name"]).To(Equal("server"))
+		})
+
+		It("should only list the latest version of each certificate", func() {
+			generate(`{"name":"server","type":"certificate","mode":"converge","parameters":{"common_name":"server","ca":"my-ca","duration":30}}`)
+
+			result := dependents(listDependents("?name=my-ca"))
+			Expect(result).To(HaveLen(2))
+
+			serverValues, _ := memoryStore.GetByName("server")
+			Expect(result[1]["id"]).To(Equal(serverValues[0].ID))
+		})
+
+		It("should flag certificates signed by a previous CA version", func() {
+			putGeneratedCertificate(memoryStore, "my-ca", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			generate(`{"name":"server","type":"certificate","mode":"converge","parameters":{"common_name":"server","ca":"my-ca"}}`)
+
+			result := dependents(listDependents("?name=my-ca"))
+			Expect(result).To(HaveLen(2))
+			Expect(result[0]["name"]).To(Equal("client"))
+			Expect(result[0]["up_to_date"]).To(BeFalse())
+			Expect(result[1]["name"]).To(Equal("server"))
+			Expect(result[1]["up_to_date"]).To(BeTrue())
+		})
+
+		It("should return an empty list if the CA issued nothing", func() {
+			putGeneratedCertificate(memoryStore, "other-ca", map[string]interface{}{"is_ca": true, "common_name": "other-ca"})
+
+			recorder := listDependents("?name=other-ca")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal(`{"data":[]}`))
+		})
+	})
+})
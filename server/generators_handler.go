@@ -0,0 +1,32 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/types"
+)
+
+type generatorsHandler struct {
+	descriptions []types.GeneratorDescription
+}
+
+func NewGeneratorsHandler(descriptions []types.GeneratorDescription) http.Handler {
+	return generatorsHandler{descriptions: descriptions}
+}
+
+func (handler generatorsHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := json.Marshal(map[string]interface{}{"data": handler.descriptions})
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, string(result), http.StatusOK)
+}
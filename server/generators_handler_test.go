@@ -0,0 +1,51 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("GeneratorsHandler", func() {
+	var handler http.Handler
+
+	BeforeEach(func() {
+		handler = NewGeneratorsHandler([]types.GeneratorDescription{
+			{Type: "password", Parameters: map[string]interface{}{"type": "object"}, Example: "secret"},
+		})
+	})
+
+	It("should return 405 Method Not Allowed for anything but GET", func() {
+		req, _ := http.NewRequest("POST", "/v1/generators", nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("lists the generator descriptions", func() {
+		req, _ := http.NewRequest("GET", "/v1/generators", nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var response map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+		Expect(response).To(Equal(map[string]interface{}{
+			"data": []interface{}{
+				map[string]interface{}{
+					"type":       "password",
+					"parameters": map[string]interface{}{"type": "object"},
+					"example":    "secret",
+				},
+			},
+		}))
+	})
+})
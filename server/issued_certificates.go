@@ -0,0 +1,122 @@
+package server
+
+import (
+	"github.com/shono09835/config-server/store"
+)
+
+// newIssuedCertificate records which CA, and which version of it, issued
+// the certificate held in the configuration, so that the OCSP responder can
+// answer for its serial and converge can detect a new signer. The store fills
+// in the ID of the configuration when saving it.
+func newIssuedCertificate(dataStore store.Store, caName string, configuration store.Configuration) (store.IssuedCertificate, error) {
+	certificate, err := parseStoredCertificate(configuration)
+	if err != nil {
+		return store.IssuedCertificate{}, err
+	}
+
+	caConfigurationID, err := signingCAConfigurationID(dataStore, caName)
+	if err != nil {
+		return store.IssuedCertificate{}, err
+	}
+
+	return store.IssuedCertificate{
+		CAName:            caName,
+		CAConfigurationID: caConfigurationID,
+		SerialNumber:      formatSerialNumber(certificate.SerialNumber),
+		Name:              configuration.Name,
+	}, nil
+}
+
+// signingCAConfigurationID returns the ID of the CA version that currently
+// signs certificates, or an empty string if the CA does not exist.
+func signingCAConfigurationID(dataStore store.Store, caName string) (string, error) {
+	versions, err := dataStore.GetByName(caName)
+	if err != nil {
+		return "", err
+	}
+
+	if len(versions) == 0 {
+		return "", nil
+	}
+
+	return signingVersion(versions).ID, nil
+}
+
+// hasIssuerChanged tells whether the certificate held in the configuration
+// is outdated with regard to its CA: either another version of the CA signs
+// now, or the set of CA versions to trust changed, e.g. during a rotation.
+func hasIssuerChanged(dataStore store.Store, caName string, configuration store.Configuration) (bool, error) {
+	signerChanged, err := hasSignerChanged(dataStore, caName, configuration)
+	if err != nil || signerChanged {
+		return signerChanged, err
+	}
+
+	return hasCABundleChanged(dataStore, caName, configuration)
+}
+
+// hasCABundleChanged tells whether the 'ca' of the certificate held in the
+// configuration differs from the CA versions that are trusted now.
+func hasCABundleChanged(dataStore store.Store, caName string, configuration store.Configuration) (bool, error) {
+	versions, err := dataStore.GetByName(caName)
+	if err != nil {
+		return false, err
+	}
+
+	if len(versions) == 0 {
+		return false, nil
+	}
+
+	certValue, err := parseCertResponse(configuration)
+	if err != nil {
+		return false, nil
+	}
+
+	caBundle, err := NewX509Loader(dataStore).LoadCABundle(caName)
+	if err != nil {
+		return false, err
+	}
+
+	return certValue.CA != caBundle, nil
+}
+
+// hasSignerChanged tells whether the certificate held in the configuration
+// was issued by another version of the CA than the one signing now.
+// Certificates recorded without a CA version are considered up to date.
+func hasSignerChanged(dataStore store.Store, caName string, configuration store.Configuration) (bool, error) {
+	certificate, err := parseStoredCertificate(configuration)
+	if err != nil {
+		return false, nil
+	}
+
+	issuedCertificate, err := dataStore.GetIssuedCertificate(formatSerialNumber(certificate.SerialNumber))
+	if err != nil {
+		return false, err
+	}
+
+	if issuedCertificate.CAName != caName || issuedCertificate.CAConfigurationID == "" {
+		return false, nil
+	}
+
+	caConfigurationID, err := signingCAConfigurationID(dataStore, caName)
+	if err != nil {
+		return false, err
+	}
+
+	return caConfigurationID != "" && caConfigurationID != issuedCertificate.CAConfigurationID, nil
+}
+
+// issuingCAName returns the name of the CA a generation request signs with,
+// or an empty string for self-signed certificates and other value types.
+func issuingCAName(generatorType string, parameters interface{}) string {
+	if generatorType != "certificate" {
+		return ""
+	}
+
+	params, ok := parameters.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	caName, _ := params["ca"].(string)
+	return caName
+}
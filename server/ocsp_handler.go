@@ -0,0 +1,250 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/shono09835/config-server/config"
+	"github.com/shono09835/config-server/log"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+const (
+	DefaultOCSPPath     = "/v1/ocsp"
+	defaultOCSPValidity = 24 * time.Hour
+
+	maxOCSPRequestSize = 10 * 1024
+)
+
+type ocspHandler struct {
+	store      store.Store
+	loader     types.CertsLoader
+	path       string
+	validity   time.Duration
+	responders map[string]string
+}
+
+func NewOCSPHandler(store store.Store, loader types.CertsLoader, config config.OCSPConfig) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+
+	path := config.Path
+	if path == "" {
+		path = DefaultOCSPPath
+	}
+
+	validity := time.Duration(config.ValidityHours) * time.Hour
+	if validity <= 0 {
+		validity = defaultOCSPValidity
+	}
+
+	return ocspHandler{
+		store:      store,
+		loader:     loader,
+		path:       strings.TrimSuffix(path, "/"),
+		validity:   validity,
+		responders: config.Responders,
+	}, nil
+}
+
+func (handler ocspHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	var rawRequest []byte
+	var err error
+
+	switch req.Method {
+	case "GET":
+		rawRequest, err = handler.readGetRequest(req)
+	case "POST":
+		if !strings.EqualFold(req.Header.Get("content-type"), "application/ocsp-request") {
+			http.Error(resWriter, NewErrorResponse(errors.Error("Unsupported Media Type - Accepts application/ocsp-request only")).GenerateErrorMsg(), http.StatusUnsupportedMediaType)
+			return
+		}
+		rawRequest, err = io.ReadAll(io.LimitReader(req.Body, maxOCSPRequestSize))
+	default:
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err != nil {
+		respondOCSP(resWriter, ocsp.MalformedRequestErrorResponse)
+		return
+	}
+
+	ocspRequest, err := ocsp.ParseRequest(rawRequest)
+	if err != nil {
+		respondOCSP(resWriter, ocsp.MalformedRequestErrorResponse)
+		return
+	}
+
+	caName := req.URL.Query().Get("ca")
+	if caName != "" {
+		if isNameValid, nameError := isValidName(caName); !isNameValid {
+			http.Error(resWriter, NewErrorResponse(nameError).GenerateErrorMsg(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	response, err := handler.createResponse(ocspRequest, caName)
+	if err != nil {
+		log.Logger.Error("ocspHandler", "Creating OCSP response: %s", err.Error())
+		respondOCSP(resWriter, ocsp.InternalErrorErrorResponse)
+		return
+	}
+
+	respondOCSP(resWriter, response)
+}
+
+// readGetRequest decodes the base64 request appended to the responder path,
+// as described in RFC 6960, Appendix A.1.
+func (handler ocspHandler) readGetRequest(req *http.Request) ([]byte, error) {
+	encoded := strings.TrimPrefix(strings.TrimPrefix(req.URL.EscapedPath(), handler.path), "/")
+	if encoded == "" {
+		return nil, errors.Error("OCSP request is missing")
+	}
+
+	encoded, err := url.PathUnescape(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+// createResponse returns the signed response for a request. The CA is taken
+// from the optional 'ca' query parameter, falling back to the CA recorded when
+// the certificate was issued; without either, the responder is not
+// authoritative and returns the 'unauthorized' response.
+func (handler ocspHandler) createResponse(ocspRequest *ocsp.Request, caName string) ([]byte, error) {
+	serial := formatSerialNumber(ocspRequest.SerialNumber)
+
+	issuedCertificate, err := handler.store.GetIssuedCertificate(serial)
+	if err != nil {
+		return nil, err
+	}
+
+	if caName == "" {
+		caName = issuedCertificate.CAName
+	}
+
+	if caName == "" {
+		return ocsp.UnauthorizedErrorResponse, nil
+	}
+
+	caCert, caKey, err := handler.loader.LoadCerts(caName)
+	if err != nil {
+		return ocsp.UnauthorizedErrorResponse, nil
+	}
+
+	matches, err := issuerKeyHashMatches(ocspRequest, caCert)
+	if err != nil {
+		return nil, err
+	}
+
+	if !matches {
+		return ocsp.UnauthorizedErrorResponse, nil
+	}
+
+	now := time.Now().UTC()
+	template := ocsp.Response{
+		Status:       ocsp.Unknown,
+		SerialNumber: ocspRequest.SerialNumber,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(handler.validity),
+		IssuerHash:   ocspRequest.HashAlgorithm,
+	}
+
+	if issuedCertificate.CAName == caName {
+		template.Status = ocsp.Good
+	}
+
+	revocations, err := handler.store.GetRevocations(caName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revocation := range revocations {
+		if revocation.SerialNumber == serial {
+			template.Status = ocsp.Revoked
+			template.RevokedAt = revocation.RevokedAt
+			template.RevocationReason = revocation.Reason
+			break
+		}
+	}
+
+	responderCert, responderKey := caCert, crypto.Signer(caKey)
+
+	if responderName, ok := handler.responders[caName]; ok {
+		delegateCert, delegateKey, err := handler.loader.LoadCerts(responderName)
+		if err != nil {
+			return nil, errors.WrapErrorf(err, "Loading OCSP responder '%s'", responderName)
+		}
+
+		err = validateResponder(delegateCert, caCert)
+		if err != nil {
+			return nil, errors.WrapErrorf(err, "Validating OCSP responder '%s'", responderName)
+		}
+
+		responderCert, responderKey = delegateCert, delegateKey
+		template.Certificate = delegateCert
+	}
+
+	return ocsp.CreateResponse(caCert, responderCert, template, responderKey)
+}
+
+// validateResponder checks that a delegated responder may sign responses for
+// the CA, as required by RFC 6960, section 4.2.2.2: it must be issued by the
+// CA and allowed to sign OCSP responses.
+func validateResponder(responderCert *x509.Certificate, caCert *x509.Certificate) error {
+	err := responderCert.CheckSignatureFrom(caCert)
+	if err != nil {
+		return errors.WrapError(err, "Responder certificate is not issued by the CA")
+	}
+
+	for _, usage := range responderCert.ExtKeyUsage {
+		if usage == x509.ExtKeyUsageOCSPSigning {
+			return nil
+		}
+	}
+
+	return errors.Error("Responder certificate does not have the 'ocsp_signing' extended key usage")
+}
+
+func issuerKeyHashMatches(ocspRequest *ocsp.Request, caCert *x509.Certificate) (bool, error) {
+	if !ocspRequest.HashAlgorithm.Available() {
+		return false, nil
+	}
+
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+
+	_, err := asn1.Unmarshal(caCert.RawSubjectPublicKeyInfo, &publicKeyInfo)
+	if err != nil {
+		return false, errors.WrapError(err, "Parsing CA public key")
+	}
+
+	hash := ocspRequest.HashAlgorithm.New()
+	hash.Write(publicKeyInfo.PublicKey.RightAlign())
+
+	return bytes.Equal(hash.Sum(nil), ocspRequest.IssuerKeyHash), nil
+}
+
+func respondOCSP(resWriter http.ResponseWriter, response []byte) {
+	resWriter.Header().Set("Content-Type", "application/ocsp-response")
+	respond(resWriter, string(response), http.StatusOK)
+}
@@ -0,0 +1,235 @@
+package server_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/shono09835/config-server/config"
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+)
+
+var _ = Describe("OCSPHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewOCSPHandler(nil, nil, config.OCSPConfig{})
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var (
+			handler     http.Handler
+			memoryStore store.Store
+			caCert      *x509.Certificate
+			leafCert    *x509.Certificate
+		)
+
+		createRequest := func(certificate *x509.Certificate) []byte {
+			request, err := ocsp.CreateRequest(certificate, caCert, &ocsp.RequestOptions{Hash: crypto.SHA256})
+			Expect(err).ToNot(HaveOccurred())
+			return request
+		}
+
+		postOCSP := func(body []byte) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("POST", "/v1/ocsp", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/ocsp-request")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		parseResponse := func(recorder *httptest.ResponseRecorder) *ocsp.Response {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/ocsp-response"))
+
+			response, err := ocsp.ParseResponseForCert(recorder.Body.Bytes(), leafCert, caCert)
+			Expect(err).ToNot(HaveOccurred())
+			return response
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			handler, _ = NewOCSPHandler(memoryStore, NewX509Loader(memoryStore), config.OCSPConfig{ValidityHours: 12})
+
+			putGeneratedCertificate(memoryStore, "my-ca", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			caCert, _, _ = NewX509Loader(memoryStore).LoadCerts("my-ca")
+
+			putGeneratedCertificate(memoryStore, "leaf", map[string]interface{}{"ca": "my-ca", "common_name": "leaf"})
+			values, _ := memoryStore.GetByName("leaf")
+			leafCert = parseStoredCertificateValue(values[0].Value)
+
+			Expect(memoryStore.PutIssuedCertificate(store.IssuedCertificate{
+				CAName:          "my-ca",
+				SerialNumber:    leafCert.SerialNumber.Text(16),
+				Name:            "leaf",
+				ConfigurationID: values[0].ID,
+			})).To(Succeed())
+		})
+
+		It("should return 405 Method Not Allowed for anything but GET and POST", func() {
+			req, _ := http.NewRequest("PUT", "/v1/ocsp", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should return 415 Unsupported Media Type for POST requests of another content type", func() {
+			req, _ := http.NewRequest("POST", "/v1/ocsp", bytes.NewReader(createRequest(leafCert)))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnsupportedMediaType))
+		})
+
+		It("returns the malformed request response when the request cannot be parsed", func() {
+			recorder := postOCSP([]byte("garbage"))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.Bytes()).To(Equal(ocsp.MalformedRequestErrorResponse))
+		})
+
+		It("reports good status for certificates that are not revoked", func() {
+			response := parseResponse(postOCSP(createRequest(leafCert)))
+
+			Expect(response.Status).To(Equal(ocsp.Good))
+			Expect(response.SerialNumber).To(Equal(leafCert.SerialNumber))
+			Expect(response.NextUpdate.Sub(response.ThisUpdate)).To(Equal(12 * time.Hour))
+		})
+
+		It("reports revoked status with the revocation time and reason", func() {
+			revokedAt := time.Unix(1500000000, 0).UTC()
+			Expect(memoryStore.PutRevocation(store.Revocation{
+				CAName:       "my-ca",
+				SerialNumber: leafCert.SerialNumber.Text(16),
+				Reason:       1,
+				RevokedAt:    revokedAt,
+			})).To(Succeed())
+
+			response := parseResponse(postOCSP(createRequest(leafCert)))
+
+			Expect(response.Status).To(Equal(ocsp.Revoked))
+			Expect(response.RevokedAt).To(Equal(revokedAt))
+			Expect(response.RevocationReason).To(Equal(ocsp.KeyCompromise))
+		})
+
+		It("accepts base64 encoded GET requests", func() {
+			encoded := url.PathEscape(base64.StdEncoding.EncodeToString(createRequest(leafCert)))
+
+			req, _ := http.NewRequest("GET", "/v1/ocsp/"+encoded, nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(parseResponse(recorder).Status).To(Equal(ocsp.Good))
+		})
+
+		It("reports unknown status for unrecorded certificates of the CA named in the request", func() {
+			putGeneratedCertificate(memoryStore, "other", map[string]interface{}{"ca": "my-ca", "common_name": "other"})
+			values, _ := memoryStore.GetByName("other")
+			leafCert = parseStoredCertificateValue(values[0].Value)
+
+			req, _ := http.NewRequest("POST", "/v1/ocsp?ca=my-ca", bytes.NewReader(createRequest(leafCert)))
+			req.Header.Set("Content-Type", "application/ocsp-request")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(parseResponse(recorder).Status).To(Equal(ocsp.Unknown))
+		})
+
+		It("should return 400 Bad Request when the CA name is invalid", func() {
+			req, _ := http.NewRequest("POST", "/v1/ocsp?ca=my!ca", bytes.NewReader(createRequest(leafCert)))
+			req.Header.Set("Content-Type", "application/ocsp-request")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns the unauthorized response for certificates it did not issue", func() {
+			putGeneratedCertificate(memoryStore, "other", map[string]interface{}{"ca": "my-ca", "common_name": "other"})
+			values, _ := memoryStore.GetByName("other")
+
+			recorder := postOCSP(createRequest(parseStoredCertificateValue(values[0].Value)))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.Bytes()).To(Equal(ocsp.UnauthorizedErrorResponse))
+		})
+
+		It("returns the unauthorized response when the issuer does not match the recorded CA", func() {
+			putGeneratedCertificate(memoryStore, "other-ca", map[string]interface{}{"is_ca": true, "common_name": "other-ca"})
+			otherCACert, _, _ := NewX509Loader(memoryStore).LoadCerts("other-ca")
+
+			request, err := ocsp.CreateRequest(leafCert, otherCACert, &ocsp.RequestOptions{Hash: crypto.SHA256})
+			Expect(err).ToNot(HaveOccurred())
+
+			recorder := postOCSP(request)
+
+			Expect(recorder.Body.Bytes()).To(Equal(ocsp.UnauthorizedErrorResponse))
+		})
+
+		It("signs responses with a delegated responder when one is configured", func() {
+			putGeneratedCertificate(memoryStore, "my-ca-ocsp", map[string]interface{}{
+				"ca":                 "my-ca",
+				"common_name":        "my-ca-ocsp",
+				"extended_key_usage": []interface{}{"ocsp_signing"},
+			})
+			responderCert, _, _ := NewX509Loader(memoryStore).LoadCerts("my-ca-ocsp")
+
+			handler, _ = NewOCSPHandler(memoryStore, NewX509Loader(memoryStore), config.OCSPConfig{
+				Responders: map[string]string{"my-ca": "my-ca-ocsp"},
+			})
+
+			response := parseResponse(postOCSP(createRequest(leafCert)))
+
+			Expect(response.Status).To(Equal(ocsp.Good))
+			Expect(response.Certificate).ToNot(BeNil())
+			Expect(response.Certificate.Equal(responderCert)).To(BeTrue())
+		})
+
+		It("returns the internal error response when the delegated responder cannot sign OCSP responses", func() {
+			putGeneratedCertificate(memoryStore, "my-ca-server", map[string]interface{}{
+				"ca":                 "my-ca",
+				"common_name":        "my-ca-server",
+				"extended_key_usage": []interface{}{"server_auth"},
+			})
+
+			handler, _ = NewOCSPHandler(memoryStore, NewX509Loader(memoryStore), config.OCSPConfig{
+				Responders: map[string]string{"my-ca": "my-ca-server"},
+			})
+
+			recorder := postOCSP(createRequest(leafCert))
+
+			Expect(recorder.Body.Bytes()).To(Equal(ocsp.InternalErrorErrorResponse))
+		})
+
+		It("returns the internal error response when the delegated responder is not issued by the CA", func() {
+			putGeneratedCertificate(memoryStore, "other-ca", map[string]interface{}{"is_ca": true, "common_name": "other-ca"})
+			putGeneratedCertificate(memoryStore, "other-ca-ocsp", map[string]interface{}{
+				"ca":                 "other-ca",
+				"common_name":        "other-ca-ocsp",
+				"extended_key_usage": []interface{}{"ocsp_signing"},
+			})
+
+			handler, _ = NewOCSPHandler(memoryStore, NewX509Loader(memoryStore), config.OCSPConfig{
+				Responders: map[string]string{"my-ca": "other-ca-ocsp"},
+			})
+
+			recorder := postOCSP(createRequest(leafCert))
+
+			Expect(recorder.Body.Bytes()).To(Equal(ocsp.InternalErrorErrorResponse))
+		})
+	})
+})
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+type regenerateHandler struct {
+	store                 store.Store
+	valueGeneratorFactory types.ValueGeneratorFactory
+}
+
+func NewRegenerateHandler(store store.Store, valueGeneratorFactory types.ValueGeneratorFactory) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+	return regenerateHandler{store: store, valueGeneratorFactory: valueGeneratorFactory}, nil
+}
+
+// ServeHTTP generates a new version of a name with the generator type and
+// parameters stored with its latest version.
+func (handler regenerateHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if contentTypeErr := validateRequestContentType(req); contentTypeErr != nil {
+		http.Error(resWriter, NewErrorResponse(contentTypeErr).GenerateErrorMsg(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	name, err := readRegenerateRequest(req)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	versions, err := handler.store.GetByName(name)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(versions) == 0 {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' not found", name)).GenerateErrorMsg(), http.StatusNotFound)
+		return
+	}
+
+	current := versions[0]
+	if current.GeneratorType == "" {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' was not generated and cannot be regenerated", name)).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	if current.RotationPhase != "" {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' is a CA being rotated and cannot be regenerated", name)).GenerateErrorMsg(), http.StatusConflict)
+		return
+	}
+
+	generator, err := handler.valueGeneratorFactory.GetGenerator(current.GeneratorType)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	// Secret parameters are not stored, so regenerating without them would
+	// silently produce a different value.
+	if secretParametersGenerator, ok := generator.(types.SecretParametersGenerator); ok && len(secretParametersGenerator.SecretParameters()) != 0 {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' has type '%s' which takes secret parameters and cannot be regenerated", name, current.GeneratorType)).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	var parameters map[string]interface{}
+	err = json.Unmarshal([]byte(current.Parameters), &parameters)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(errors.WrapError(err, "Failed to parse stored parameters")).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	generatedValue, err := generateValue(generator, name, parameters, versions)
+	if err != nil {
+		if _, inProgress := err.(types.GenerationInProgressError); inProgress {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	// The previous checksum is kept so that converging with the original
+	// parameters does not regenerate the value once more.
+	configuration, err := saveGenerated(handler.store, store.Configuration{
+		Name:              name,
+		ParameterChecksum: current.ParameterChecksum,
+		GeneratorType:     current.GeneratorType,
+		Parameters:        current.Parameters,
+	}, generatedValue, issuingCAName(current.GeneratorType, parameters))
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	result, _ := configuration.StringifiedJSON()
+	respond(resWriter, result, http.StatusCreated)
+}
+
+func readRegenerateRequest(req *http.Request) (string, error) {
+	jsonMap, err := readJSONBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := getStringValueFromJSONBody(jsonMap, "name")
+	if err != nil {
+		return "", err
+	}
+
+	if isNameValid, nameError := isValidName(name); !isNameValid {
+		return "", nameError
+	}
+
+	return name, nil
+}
@@ -0,0 +1,170 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/shono09835/config-server/config"
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("RegenerateHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewRegenerateHandler(nil, nil)
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var (
+			handler               http.Handler
+			memoryStore           store.Store
+			valueGeneratorFactory types.ValueGeneratorFactory
+		)
+
+		post := func(target http.Handler, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+			bodyBytes, _ := json.Marshal(body)
+			req, _ := generateHTTPRequest("POST", path, bytes.NewReader(bodyBytes))
+			recorder := httptest.NewRecorder()
+			target.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		generate := func(name string, valueType string, parameters map[string]interface{}) {
+			requestHandler, _ := NewRequestHandler(memoryStore, valueGeneratorFactory)
+			recorder := post(requestHandler, "/v1/data", map[string]interface{}{"name": name, "type": valueType, "parameters": parameters})
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		}
+
+		regenerate := func(name string) *httptest.ResponseRecorder {
+			return post(handler, "/v1/data/regenerate", map[string]interface{}{"name": name})
+		}
+
+		latest := func(name string) store.Configuration {
+			configurations, err := memoryStore.GetByName(name)
+			Expect(err).ToNot(HaveOccurred())
+			return configurations[0]
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			valueGeneratorFactory = types.NewValueGeneratorConcrete(NewX509Loader(memoryStore), NewSSHKeyLoader(memoryStore), config.GeneratorsConfig{})
+			handler, _ = NewRegenerateHandler(memoryStore, valueGeneratorFactory)
+		})
+
+		It("should return 405 Method Not Allowed for anything but POST", func() {
+			req, _ := http.NewRequest("GET", "/v1/data/regenerate", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should return 400 Bad Request when the name is missing", func() {
+			recorder := post(handler, "/v1/data/regenerate", map[string]interface{}{})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("JSON request body should contain the key 'name'"))
+		})
+
+		It("should return 404 Not Found for unknown names", func() {
+			recorder := regenerate("missing")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(ContainSubstring("Name 'missing' not found"))
+		})
+
+		It("should return 400 Bad Request for values that were not generated", func() {
+			_, err := memoryStore.Put("imported", `{"value":"blue"}`, "")
+			Expect(err).ToNot(HaveOccurred())
+
+			recorder := regenerate("imported")
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Name 'imported' was not generated and cannot be regenerated"))
+		})
+
+		It("should generate a new version with the stored type and parameters", func() {
+			generate("password", "password", map[string]interface{}{"length": 30})
+			previous := latest("password")
+
+			recorder := regenerate("password")
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+			regenerated := latest("password")
+			Expect(regenerated.ID).ToNot(Equal(previous.ID))
+			Expect(regenerated.Value).ToNot(Equal(previous.Value))
+			Expect(regenerated.ParameterChecksum).To(Equal(previous.ParameterChecksum))
+			Expect(regenerated.GeneratorType).To(Equal("password"))
+			Expect(regenerated.Parameters).To(Equal(`{"length":30}`))
+
+			var response map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response["id"]).To(Equal(regenerated.ID))
+			Expect(response["value"]).To(HaveLen(30))
+		})
+
+		It("should record the CA that issued a regenerated certificate", func() {
+			generate("my-ca", "certificate", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			generate("leaf", "certificate", map[string]interface{}{"ca": "my-ca", "common_name": "leaf"})
+			previous := parseStoredCertificateValue(latest("leaf").Value)
+
+			recorder := regenerate("leaf")
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+			regenerated := latest("leaf")
+			certificate := parseStoredCertificateValue(regenerated.Value)
+			Expect(certificate.SerialNumber).ToNot(Equal(previous.SerialNumber))
+			Expect(certificate.Subject.CommonName).To(Equal("leaf"))
+			Expect(certificate.CheckSignatureFrom(parseStoredCertificateValue(latest("my-ca").Value))).To(Succeed())
+
+			issuedCertificate, err := memoryStore.GetIssuedCertificate(certificate.SerialNumber.Text(16))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(issuedCertificate.CAName).To(Equal("my-ca"))
+			Expect(issuedCertificate.ConfigurationID).To(Equal(regenerated.ID))
+		})
+
+		It("should return 409 Conflict for CAs being rotated", func() {
+			generate("my-ca", "certificate", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			Expect(memoryStore.SetRotationPhase(latest("my-ca").ID, RotationPhaseTransitional)).To(Succeed())
+
+			recorder := regenerate("my-ca")
+
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+		})
+
+		It("should return 400 Bad Request for types that take secret parameters", func() {
+			scriptDir, err := os.MkdirTemp("", "external-generator")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(scriptDir) //nolint:errcheck
+
+			scriptPath := filepath.Join(scriptDir, "generator")
+			Expect(os.WriteFile(scriptPath, []byte("#!/bin/sh\ncat > /dev/null\necho '{\"value\":\"token\"}'\n"), 0700)).To(Succeed())
+
+			valueGeneratorFactory = types.NewValueGeneratorConcrete(NewX509Loader(memoryStore), NewSSHKeyLoader(memoryStore), config.GeneratorsConfig{
+				External: map[string]config.ExternalGeneratorConfig{
+					"vault_token": {Path: scriptPath, SecretParameters: []string{"root_token"}},
+				},
+			})
+			handler, _ = NewRegenerateHandler(memoryStore, valueGeneratorFactory)
+
+			generate("token", "vault_token", map[string]interface{}{"root_token": "s.secret"})
+
+			recorder := regenerate("token")
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("takes secret parameters and cannot be regenerated"))
+		})
+	})
+})
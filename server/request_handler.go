@@ -44,21 +44,33 @@ func (handler requestHandler) ServeHTTP(resWriter http.ResponseWriter, req *http
 	}
 }
 
+// PKCS12PasswordHeader carries the password of PKCS#12 archives returned by
+// GET, so that it does not end up in access logs along with the URL.
+const PKCS12PasswordHeader = "X-PKCS12-Password"
+
 func (handler requestHandler) handleGet(resWriter http.ResponseWriter, req *http.Request) {
+	format := req.URL.Query().Get("format")
+
+	if req.URL.Query().Has("password") {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Passwords are not accepted in the URL, use the '%s' header", PKCS12PasswordHeader)).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+	password := req.Header.Get(PKCS12PasswordHeader)
+
 	id, idErr := extractIDFromURLPath(req.URL.Path)
 	if idErr == nil {
-		handler.handleGetByID(id, resWriter)
+		handler.handleGetByID(id, format, password, resWriter)
 	} else {
 		name := req.URL.Query().Get("name")
 		if len(name) == 0 {
 			http.Error(resWriter, NewErrorResponse(idErr).GenerateErrorMsg(), http.StatusBadRequest)
 		} else {
-			handler.handleGetByName(name, resWriter)
+			handler.handleGetByName(name, format, password, resWriter)
 		}
 	}
 }
 
-func (handler requestHandler) handleGetByID(id string, resWriter http.ResponseWriter) {
+func (handler requestHandler) handleGetByID(id string, format string, password string, resWriter http.ResponseWriter) {
 
 	value, err := handler.store.GetByID(id)
 
@@ -72,12 +84,18 @@ func (handler requestHandler) handleGetByID(id string, resWriter http.ResponseWr
 	if value == emptyValue {
 		http.Error(resWriter, NewErrorResponse(errors.Errorf("ID '%s' not found", id)).GenerateErrorMsg(), http.StatusNotFound)
 	} else {
+		value, err = convertConfigurationFormat(value, format, password)
+		if err != nil {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+			return
+		}
+
 		result, _ := value.StringifiedJSON()
 		respond(resWriter, result, http.StatusOK)
 	}
 }
 
-func (handler requestHandler) handleGetByName(name string, resWriter http.ResponseWriter) {
+func (handler requestHandler) handleGetByName(name string, format string, password string, resWriter http.ResponseWriter) {
 
 	if isNameValid, nameError := isValidName(name); !isNameValid {
 		http.Error(resWriter, NewErrorResponse(nameError).GenerateErrorMsg(), http.StatusBadRequest)
@@ -93,6 +111,15 @@ func (handler requestHandler) handleGetByName(name string, resWriter http.Respon
 	if len(values) == 0 {
 		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' not found", name)).GenerateErrorMsg(), http.StatusNotFound)
 	} else {
+		values = signingOrder(values)
+		for i, value := range values {
+			values[i], err = convertConfigurationFormat(value, format, password)
+			if err != nil {
+				http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+				return
+			}
+		}
+
 		result, err := values.StringifiedJSON()
 		if err == nil {
 			respond(resWriter, result, http.StatusOK)
@@ -100,19 +127,65 @@ func (handler requestHandler) handleGetByName(name string, resWriter http.Respon
 	}
 }
 
+// convertConfigurationFormat re-encodes the value of a configuration in the
+// requested format. The stored value is left untouched.
+func convertConfigurationFormat(configuration store.Configuration, format string, password string) (store.Configuration, error) {
+	if format == "" || format == types.ValueFormatPEM {
+		return configuration, nil
+	}
+
+	var container struct {
+		Value interface{} `json:"value"`
+	}
+
+	err := json.Unmarshal([]byte(configuration.Value), &container)
+	if err != nil {
+		return store.Configuration{}, errors.WrapError(err, "Failed to parse value")
+	}
+
+	converted, err := types.ConvertValueFormat(container.Value, format, password)
+	if err != nil {
+		return store.Configuration{}, err
+	}
+
+	bytes, err := json.Marshal(map[string]interface{}{"value": converted})
+	if err != nil {
+		return store.Configuration{}, err
+	}
+
+	configuration.Value = string(bytes)
+	return configuration, nil
+}
+
 func (handler requestHandler) handlePut(resWriter http.ResponseWriter, req *http.Request) {
 	if contentTypeErr := validateRequestContentType(req); contentTypeErr != nil {
 		http.Error(resWriter, NewErrorResponse(contentTypeErr).GenerateErrorMsg(), http.StatusUnsupportedMediaType)
 		return
 	}
 
-	name, value, err := readPutRequest(req)
+	name, value, valueType, err := readPutRequest(req)
 
 	if err != nil {
 		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
 		return
 	}
 
+	if err = validateValue(valueType, value); err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	values, err := handler.store.GetByName(name)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(values) != 0 && values[0].RotationPhase != "" {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' is a CA being rotated and cannot be overwritten", name)).GenerateErrorMsg(), http.StatusConflict)
+		return
+	}
+
 	configuration, err := handler.saveToStore(name, value, "")
 
 	if err != nil {
@@ -142,14 +215,48 @@ func (handler requestHandler) handlePost(resWriter http.ResponseWriter, req *htt
 		return
 	}
 
-	checksum, err := handler.calculateChecksum(parameters)
+	if len(values) != 0 && "converge" != mode {
+		result, err := values[0].StringifiedJSON()
+		if err != nil {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		} else {
+			respond(resWriter, result, http.StatusOK)
+		}
+		return
+	}
+
+	generator, err := handler.valueGeneratorFactory.GetGenerator(generatorType)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	checksum, err := calculateChecksum(generator, parameters)
 	if err != nil {
 		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
 	}
 
 	if len(values) != 0 {
 		configuration := values[0]
-		if "converge" != mode || checksum == configuration.ParameterChecksum {
+
+		upToDate, err := checksumMatches(configuration.ParameterChecksum, checksum, parameters)
+		if err != nil {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+			return
+		}
+		if upToDate {
+			if caName := issuingCAName(generatorType, parameters); caName != "" {
+				issuerChanged, err := hasIssuerChanged(handler.store, caName, configuration)
+				if err != nil {
+					http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+					return
+				}
+				upToDate = !issuerChanged
+			}
+		}
+
+		if upToDate {
 			result, err := configuration.StringifiedJSON()
 			if err != nil {
 				http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
@@ -158,21 +265,35 @@ func (handler requestHandler) handlePost(resWriter http.ResponseWriter, req *htt
 			}
 			return
 		}
+
+		if configuration.RotationPhase != "" {
+			http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' is a CA being rotated and cannot be regenerated", name)).GenerateErrorMsg(), http.StatusConflict)
+			return
+		}
 	}
 
-	generator, err := handler.valueGeneratorFactory.GetGenerator(generatorType)
+	generatedValue, err := generateValue(generator, name, parameters, values)
 	if err != nil {
+		if _, inProgress := err.(types.GenerationInProgressError); inProgress {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusServiceUnavailable)
+			return
+		}
 		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
 		return
 	}
 
-	generatedValue, err := generator.Generate(parameters)
+	normalizedParameters, err := types.NormalizeParameters(generator, parameters)
 	if err != nil {
 		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
 		return
 	}
 
-	configuration, err := handler.saveToStore(name, generatedValue, checksum)
+	configuration, err := saveGenerated(handler.store, store.Configuration{
+		Name:              name,
+		ParameterChecksum: checksum,
+		GeneratorType:     generatorType,
+		Parameters:        normalizedParameters,
+	}, generatedValue, issuingCAName(generatorType, parameters))
 	if err != nil {
 		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
 		return
@@ -182,14 +303,47 @@ func (handler requestHandler) handlePost(resWriter http.ResponseWriter, req *htt
 	respond(resWriter, result, http.StatusCreated)
 }
 
-func (handler requestHandler) calculateChecksum(v interface{}) (string, error) {
-	result, err := json.Marshal(v)
+// generateValue runs the generator, handing it the latest stored version
+// when it can build on it.
+func generateValue(generator types.ValueGenerator, name string, parameters interface{}, values store.Configurations) (interface{}, error) {
+	if previousValueGenerator, ok := generator.(types.PreviousValueGenerator); ok && len(values) != 0 {
+		return previousValueGenerator.GenerateFromPrevious(parameters, values[0].Value)
+	}
+
+	if namedValueGenerator, ok := generator.(types.NamedValueGenerator); ok {
+		return namedValueGenerator.GenerateForName(name, parameters)
+	}
+
+	return generator.Generate(parameters)
+}
+
+// calculateChecksum digests the parameters of a generation. Secret
+// parameters of the generator are left out, so that neither they nor a
+// digest of them are stored.
+func calculateChecksum(generator types.ValueGenerator, parameters interface{}) (string, error) {
+	result, err := json.Marshal(types.WithoutSecretParameters(generator, parameters))
 	if err != nil {
 		return "", errors.WrapError(err, "Calculating checksum:")
 	}
 
-	checksum := sha256.New().Sum(result)
-	return hex.EncodeToString(checksum), nil
+	checksum := sha256.Sum256(result)
+	return hex.EncodeToString(checksum[:]), nil
+}
+
+// checksumMatches tells whether a stored checksum was calculated from the
+// given parameters. Values stored by earlier versions carry the hex encoded
+// parameters followed by an empty digest instead of a digest of them.
+func checksumMatches(storedChecksum string, checksum string, parameters interface{}) (bool, error) {
+	if storedChecksum == checksum {
+		return true, nil
+	}
+
+	result, err := json.Marshal(parameters)
+	if err != nil {
+		return false, errors.WrapError(err, "Calculating checksum:")
+	}
+
+	return storedChecksum == hex.EncodeToString(sha256.New().Sum(result)), nil
 }
 
 func (handler requestHandler) handleDelete(resWriter http.ResponseWriter, req *http.Request) {
@@ -213,6 +367,10 @@ func (handler requestHandler) handleDelete(resWriter http.ResponseWriter, req *h
 }
 
 func (handler requestHandler) saveToStore(name string, value interface{}, checksum string) (store.Configuration, error) {
+	return saveToStore(handler.store, name, value, checksum)
+}
+
+func saveToStore(dataStore store.Store, name string, value interface{}, checksum string) (store.Configuration, error) {
 	configValue := make(map[string]interface{})
 	configValue["value"] = value
 
@@ -222,15 +380,43 @@ func (handler requestHandler) saveToStore(name string, value interface{}, checks
 		return store.Configuration{}, err
 	}
 
-	id, err := handler.store.Put(name, string(bytes), checksum)
+	id, err := dataStore.Put(name, string(bytes), checksum)
 	if err != nil {
 		return store.Configuration{}, err
 	}
 
-	configuration, err := handler.store.GetByID(id)
+	configuration, err := dataStore.GetByID(id)
 	return configuration, err
 }
 
+// saveGenerated stores a generated value with the configuration's name,
+// checksum, rotation phase, generator type and normalized parameters. For
+// certificates issued by caName, the issuance is recorded in the same write,
+// so that neither is stored without the other.
+func saveGenerated(dataStore store.Store, configuration store.Configuration, value interface{}, caName string) (store.Configuration, error) {
+	bytes, err := json.Marshal(map[string]interface{}{"value": value})
+	if err != nil {
+		return store.Configuration{}, err
+	}
+	configuration.Value = string(bytes)
+
+	var issuedCertificates store.IssuedCertificates
+	if caName != "" {
+		issuedCertificate, err := newIssuedCertificate(dataStore, caName, configuration)
+		if err != nil {
+			return store.Configuration{}, err
+		}
+		issuedCertificates = append(issuedCertificates, issuedCertificate)
+	}
+
+	id, err := dataStore.PutGenerated(configuration, issuedCertificates)
+	if err != nil {
+		return store.Configuration{}, err
+	}
+
+	return dataStore.GetByID(id)
+}
+
 func respond(res http.ResponseWriter, message string, status int) {
 	res.WriteHeader(status)
 
@@ -240,28 +426,33 @@ func respond(res http.ResponseWriter, message string, status int) {
 	}
 }
 
-func readPutRequest(req *http.Request) (string, interface{}, error) {
+func readPutRequest(req *http.Request) (string, interface{}, string, error) {
 
 	jsonMap, err := readJSONBody(req)
 	if err != nil {
-		return "", nil, err
+		return "", nil, "", err
 	}
 
 	name, err := getStringValueFromJSONBody(jsonMap, "name")
 	if err != nil {
-		return "", nil, err
+		return "", nil, "", err
 	}
 
 	if isNameValid, nameError := isValidName(name); !isNameValid {
-		return "", nil, nameError
+		return "", nil, "", nameError
 	}
 
 	value, keyExists := jsonMap["value"]
 	if !keyExists {
-		return "", nil, errors.Error("JSON request body should contain the key 'value'")
+		return "", nil, "", errors.Error("JSON request body should contain the key 'value'")
+	}
+
+	valueType, err := getOptionalStringValueFromJSONBody(jsonMap, "type", "")
+	if err != nil {
+		return "", nil, "", err
 	}
 
-	return name, value, nil
+	return name, value, valueType, nil
 }
 
 func readPostRequest(req *http.Request) (string, string, interface{}, string, error) {
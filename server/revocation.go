@@ -0,0 +1,80 @@
+package server
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/store"
+)
+
+// revocationReasons maps the supported CRL reason codes (RFC 5280, 5.3.1) to
+// the names accepted by the API.
+var revocationReasons = map[string]int{
+	"unspecified":            0,
+	"key_compromise":         1,
+	"ca_compromise":          2,
+	"affiliation_changed":    3,
+	"superseded":             4,
+	"cessation_of_operation": 5,
+	"privilege_withdrawn":    9,
+	"aa_compromise":          10,
+}
+
+func revocationReasonName(code int) string {
+	for name, reasonCode := range revocationReasons {
+		if reasonCode == code {
+			return name
+		}
+	}
+	return "unspecified"
+}
+
+// formatSerialNumber renders a serial number as lowercase hex, the form in
+// which revocations are stored.
+func formatSerialNumber(serialNumber *big.Int) string {
+	return serialNumber.Text(16)
+}
+
+// parseSerialNumber accepts a hex serial number, optionally colon separated
+// as printed by openssl.
+func parseSerialNumber(serial string) (*big.Int, error) {
+	hex := strings.TrimPrefix(strings.ReplaceAll(strings.ToLower(serial), ":", ""), "0x")
+
+	serialNumber, ok := new(big.Int).SetString(hex, 16)
+	if !ok || hex == "" {
+		return nil, errors.Errorf("Serial '%s' must be a hex encoded number", serial)
+	}
+
+	return serialNumber, nil
+}
+
+// parseStoredCertificate returns the certificate held in the 'certificate'
+// attribute of a stored value.
+func parseStoredCertificate(configuration store.Configuration) (*x509.Certificate, error) {
+	var certContainer struct {
+		Value struct {
+			Certificate string `json:"certificate"`
+		} `json:"value"`
+	}
+
+	err := json.Unmarshal([]byte(configuration.Value), &certContainer)
+	if err != nil || certContainer.Value.Certificate == "" {
+		return nil, errors.Errorf("Name '%s' is not a certificate", configuration.Name)
+	}
+
+	block, _ := pem.Decode([]byte(certContainer.Value.Certificate))
+	if block == nil {
+		return nil, errors.Errorf("Name '%s' is not a certificate", configuration.Name)
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.WrapErrorf(err, "Failed to parse certificate '%s'", configuration.Name)
+	}
+
+	return certificate, nil
+}
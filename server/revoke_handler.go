@@ -0,0 +1,213 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+type revokeHandler struct {
+	store  store.Store
+	loader types.CertsLoader
+}
+
+type revocationResponse struct {
+	CA           string `json:"ca"`
+	SerialNumber string `json:"serial_number"`
+	Reason       string `json:"reason"`
+	RevokedAt    string `json:"revoked_at"`
+}
+
+type revokeRequest struct {
+	ca     string
+	name   string
+	id     string
+	serial string
+	reason string
+}
+
+func NewRevokeHandler(store store.Store, loader types.CertsLoader) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+	return revokeHandler{store: store, loader: loader}, nil
+}
+
+func (handler revokeHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if contentTypeErr := validateRequestContentType(req); contentTypeErr != nil {
+		http.Error(resWriter, NewErrorResponse(contentTypeErr).GenerateErrorMsg(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	request, err := readRevokeRequest(req)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	caCert, _, err := handler.loader.LoadCerts(request.ca)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(errors.WrapError(err, "Loading CA")).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	var serialNumber string
+
+	if request.serial != "" {
+		parsedSerial, err := parseSerialNumber(request.serial)
+		if err != nil {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+			return
+		}
+		serialNumber = formatSerialNumber(parsedSerial)
+	} else {
+		configuration, status, err := handler.findConfiguration(request)
+		if err != nil {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), status)
+			return
+		}
+
+		certificate, err := parseStoredCertificate(configuration)
+		if err != nil {
+			http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+			return
+		}
+
+		if certificate.CheckSignatureFrom(caCert) != nil {
+			http.Error(resWriter, NewErrorResponse(errors.Errorf("Certificate '%s' was not issued by CA '%s'", configuration.Name, request.ca)).GenerateErrorMsg(), http.StatusBadRequest)
+			return
+		}
+		serialNumber = formatSerialNumber(certificate.SerialNumber)
+	}
+
+	revocations, err := handler.store.GetRevocations(request.ca)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, revocation := range revocations {
+		if revocation.SerialNumber == serialNumber {
+			respondWithRevocation(resWriter, revocation, http.StatusOK)
+			return
+		}
+	}
+
+	revocation := store.Revocation{
+		CAName:       request.ca,
+		SerialNumber: serialNumber,
+		Reason:       revocationReasons[request.reason],
+		RevokedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+
+	err = handler.store.PutRevocation(revocation)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithRevocation(resWriter, revocation, http.StatusCreated)
+}
+
+func (handler revokeHandler) findConfiguration(request revokeRequest) (store.Configuration, int, error) {
+	if request.id != "" {
+		configuration, err := handler.store.GetByID(request.id)
+		if err != nil {
+			return configuration, http.StatusInternalServerError, err
+		}
+		if configuration == (store.Configuration{}) {
+			return configuration, http.StatusNotFound, errors.Errorf("ID '%s' not found", request.id)
+		}
+		return configuration, http.StatusOK, nil
+	}
+
+	configurations, err := handler.store.GetByName(request.name)
+	if err != nil {
+		return store.Configuration{}, http.StatusInternalServerError, err
+	}
+	if len(configurations) == 0 {
+		return store.Configuration{}, http.StatusNotFound, errors.Errorf("Name '%s' not found", request.name)
+	}
+	return configurations[0], http.StatusOK, nil
+}
+
+func respondWithRevocation(resWriter http.ResponseWriter, revocation store.Revocation, status int) {
+	result, err := json.Marshal(revocationResponse{
+		CA:           revocation.CAName,
+		SerialNumber: revocation.SerialNumber,
+		Reason:       revocationReasonName(revocation.Reason),
+		RevokedAt:    revocation.RevokedAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, string(result), status)
+}
+
+func readRevokeRequest(req *http.Request) (revokeRequest, error) {
+	var request revokeRequest
+
+	jsonMap, err := readJSONBody(req)
+	if err != nil {
+		return request, err
+	}
+
+	request.ca, err = getStringValueFromJSONBody(jsonMap, "ca")
+	if err != nil {
+		return request, err
+	}
+
+	request.name, err = getOptionalStringValueFromJSONBody(jsonMap, "name", "")
+	if err != nil {
+		return request, err
+	}
+
+	request.id, err = getOptionalStringValueFromJSONBody(jsonMap, "id", "")
+	if err != nil {
+		return request, err
+	}
+
+	request.serial, err = getOptionalStringValueFromJSONBody(jsonMap, "serial", "")
+	if err != nil {
+		return request, err
+	}
+
+	request.reason, err = getOptionalStringValueFromJSONBody(jsonMap, "reason", "unspecified")
+	if err != nil {
+		return request, err
+	}
+
+	if _, ok := revocationReasons[request.reason]; !ok {
+		return request, errors.Errorf("Unsupported revocation reason: %s", request.reason)
+	}
+
+	identifiers := 0
+	for _, identifier := range []string{request.name, request.id, request.serial} {
+		if identifier != "" {
+			identifiers++
+		}
+	}
+
+	if identifiers != 1 {
+		return request, errors.Error("JSON request body should contain exactly one of the keys 'name', 'id' or 'serial'")
+	}
+
+	if request.name != "" {
+		if isNameValid, nameError := isValidName(request.name); !isNameValid {
+			return request, nameError
+		}
+	}
+
+	return request, nil
+}
@@ -0,0 +1,160 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	. "github.com/shono09835/config-server/store/storefakes"
+	"github.com/shono09835/config-server/types"
+)
+
+func putGeneratedCertificate(dataStore store.Store, name string, params map[string]interface{}) types.CertResponse {
+	value, err := types.NewCertificateGenerator(NewX509Loader(dataStore)).Generate(params)
+	Expect(err).ToNot(HaveOccurred())
+
+	bytes, _ := json.Marshal(map[string]interface{}{"value": value})
+	_, err = dataStore.Put(name, string(bytes), "")
+	Expect(err).ToNot(HaveOccurred())
+
+	return value.(types.CertResponse)
+}
+
+var _ = Describe("RevokeHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewRevokeHandler(nil, nil)
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var (
+			handler     http.Handler
+			memoryStore store.Store
+		)
+
+		revokeRequest := func(body map[string]interface{}) *httptest.ResponseRecorder {
+			bodyBytes, _ := json.Marshal(body)
+			req, _ := http.NewRequest("POST", "/v1/revoke", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			handler, _ = NewRevokeHandler(memoryStore, NewX509Loader(memoryStore))
+
+			putGeneratedCertificate(memoryStore, "my-ca", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			putGeneratedCertificate(memoryStore, "other-ca", map[string]interface{}{"is_ca": true, "common_name": "other-ca"})
+			putGeneratedCertificate(memoryStore, "my-cert", map[string]interface{}{"ca": "my-ca", "common_name": "my-cert"})
+		})
+
+		It("should return 405 Method Not Allowed for anything but POST", func() {
+			req, _ := http.NewRequest("GET", "/v1/revoke", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should return 400 Bad Request when more than one identifier is given", func() {
+			recorder := revokeRequest(map[string]interface{}{"ca": "my-ca", "name": "my-cert", "serial": "01"})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("JSON request body should contain exactly one of the keys 'name', 'id' or 'serial'"))
+		})
+
+		It("should return 400 Bad Request for unsupported reasons", func() {
+			recorder := revokeRequest(map[string]interface{}{"ca": "my-ca", "serial": "01", "reason": "bored"})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Unsupported revocation reason: bored"))
+		})
+
+		It("should return 400 Bad Request when the CA does not exist", func() {
+			recorder := revokeRequest(map[string]interface{}{"ca": "missing-ca", "serial": "01"})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Loading CA: No certificate found"))
+		})
+
+		It("should return 404 Not Found when the name does not exist", func() {
+			recorder := revokeRequest(map[string]interface{}{"ca": "my-ca", "name": "missing"})
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return 400 Bad Request when the certificate was issued by another CA", func() {
+			recorder := revokeRequest(map[string]interface{}{"ca": "other-ca", "name": "my-cert"})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Certificate 'my-cert' was not issued by CA 'other-ca'"))
+		})
+
+		It("should return 400 Bad Request when the serial is not hex", func() {
+			recorder := revokeRequest(map[string]interface{}{"ca": "my-ca", "serial": "xyz"})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Serial 'xyz' must be a hex encoded number"))
+		})
+
+		It("revokes a certificate by name", func() {
+			configurations, _ := memoryStore.GetByName("my-cert")
+			certificate := parseStoredCertificateValue(configurations[0].Value)
+
+			recorder := revokeRequest(map[string]interface{}{"ca": "my-ca", "name": "my-cert", "reason": "key_compromise"})
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+			var response map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response["ca"]).To(Equal("my-ca"))
+			Expect(response["serial_number"]).To(Equal(certificate.SerialNumber.Text(16)))
+			Expect(response["reason"]).To(Equal("key_compromise"))
+
+			revocations, _ := memoryStore.GetRevocations("my-ca")
+			Expect(revocations).To(HaveLen(1))
+			Expect(revocations[0].SerialNumber).To(Equal(certificate.SerialNumber.Text(16)))
+			Expect(revocations[0].Reason).To(Equal(1))
+		})
+
+		It("revokes a certificate by id", func() {
+			configurations, _ := memoryStore.GetByName("my-cert")
+
+			recorder := revokeRequest(map[string]interface{}{"ca": "my-ca", "id": configurations[0].ID})
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+
+		It("revokes a colon separated serial and returns the existing revocation when repeated", func() {
+			recorder := revokeRequest(map[string]interface{}{"ca": "my-ca", "serial": "0A:BC"})
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"serial_number":"abc"`))
+
+			recorder = revokeRequest(map[string]interface{}{"ca": "my-ca", "serial": "abc"})
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			revocations, _ := memoryStore.GetRevocations("my-ca")
+			Expect(revocations).To(HaveLen(1))
+		})
+
+		It("should return 500 Internal Server Error when recording the revocation fails", func() {
+			mockStore := &FakeStore{}
+			mockStore.PutRevocationReturns(errors.New("fake-error"))
+			handler, _ = NewRevokeHandler(mockStore, NewX509Loader(memoryStore))
+
+			recorder := revokeRequest(map[string]interface{}{"ca": "my-ca", "serial": "abc"})
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+const (
+	rotationStepTransition = "transition"
+	rotationStepPromote    = "promote"
+	rotationStepFinish     = "finish"
+)
+
+type rotateHandler struct {
+	store                 store.Store
+	valueGeneratorFactory types.ValueGeneratorFactory
+}
+
+type rotateRequest struct {
+	name       string
+	phase      string
+	parameters map[string]interface{}
+}
+
+func NewRotateHandler(store store.Store, valueGeneratorFactory types.ValueGeneratorFactory) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+	return rotateHandler{store: store, valueGeneratorFactory: valueGeneratorFactory}, nil
+}
+
+func (handler rotateHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if contentTypeErr := validateRequestContentType(req); contentTypeErr != nil {
+		http.Error(resWriter, NewErrorResponse(contentTypeErr).GenerateErrorMsg(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	request, err := readRotateRequest(req)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	versions, err := handler.store.GetByName(request.name)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(versions) == 0 {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' not found", request.name)).GenerateErrorMsg(), http.StatusNotFound)
+		return
+	}
+
+	certificate, err := parseStoredCertificate(versions[0])
+	if err != nil || !certificate.IsCA {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' is not a CA", request.name)).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	switch request.phase {
+	case rotationStepTransition:
+		handler.handleTransition(resWriter, request, versions[0])
+	case rotationStepPromote:
+		handler.setPhase(resWriter, versions[0], RotationPhaseTransitional, RotationPhasePromoted)
+	case rotationStepFinish:
+		handler.setPhase(resWriter, versions[0], RotationPhasePromoted, "")
+	}
+}
+
+// handleTransition stores a new version of the CA which is trusted alongside
+// the current one, but not used for signing yet.
+func (handler rotateHandler) handleTransition(resWriter http.ResponseWriter, request rotateRequest, current store.Configuration) {
+	if current.RotationPhase != "" {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("CA '%s' is already being rotated", current.Name)).GenerateErrorMsg(), http.StatusConflict)
+		return
+	}
+
+	request.parameters["is_ca"] = true
+
+	generator, err := handler.valueGeneratorFactory.GetGenerator("certificate")
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	generatedValue, err := generator.Generate(request.parameters)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	checksum, err := calculateChecksum(generator, request.parameters)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	normalizedParameters, err := types.NormalizeParameters(generator, request.parameters)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	configuration, err := saveGenerated(handler.store, store.Configuration{
+		Name:              request.name,
+		ParameterChecksum: checksum,
+		RotationPhase:     RotationPhaseTransitional,
+		GeneratorType:     "certificate",
+		Parameters:        normalizedParameters,
+	}, generatedValue, issuingCAName("certificate", request.parameters))
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	result, _ := configuration.StringifiedJSON()
+	respond(resWriter, result, http.StatusCreated)
+}
+
+func (handler rotateHandler) setPhase(resWriter http.ResponseWriter, current store.Configuration, expectedPhase string, phase string) {
+	if current.RotationPhase != expectedPhase {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("CA '%s' is not in rotation phase '%s'", current.Name, expectedPhase)).GenerateErrorMsg(), http.StatusConflict)
+		return
+	}
+
+	err := handler.store.SetRotationPhase(current.ID, phase)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+	current.RotationPhase = phase
+
+	result, _ := current.StringifiedJSON()
+	respond(resWriter, result, http.StatusOK)
+}
+
+func readRotateRequest(req *http.Request) (rotateRequest, error) {
+	var request rotateRequest
+
+	jsonMap, err := readJSONBody(req)
+	if err != nil {
+		return request, err
+	}
+
+	request.name, err = getStringValueFromJSONBody(jsonMap, "name")
+	if err != nil {
+		return request, err
+	}
+
+	if isNameValid, nameError := isValidName(request.name); !isNameValid {
+		return request, nameError
+	}
+
+	request.phase, err = getStringValueFromJSONBody(jsonMap, "phase")
+	if err != nil {
+		return request, err
+	}
+
+	switch request.phase {
+	case rotationStepTransition:
+		parameters, ok := jsonMap["parameters"].(map[string]interface{})
+		if !ok {
+			return request, errors.Errorf("JSON request body key 'parameters' must be an object for phase '%s'", rotationStepTransition)
+		}
+		request.parameters = parameters
+	case rotationStepPromote, rotationStepFinish:
+	default:
+		return request, errors.Errorf("Unsupported rotation phase '%s'", request.phase)
+	}
+
+	return request, nil
+}
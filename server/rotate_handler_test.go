@@ -0,0 +1,257 @@
+package server_test
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/shono09835/config-server/config"
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+func parseCertificatePEM(certificate string) *x509.Certificate {
+	block, _ := pem.Decode([]byte(certificate))
+	Expect(block).ToNot(BeNil())
+
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	Expect(err).ToNot(HaveOccurred())
+	return parsed
+}
+
+var _ = Describe("RotateHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewRotateHandler(nil, nil)
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var (
+			handler     http.Handler
+			memoryStore store.Store
+			loader      types.CertsLoader
+			oldCA       types.CertResponse
+		)
+
+		rotateRequest := func(body map[string]interface{}) *httptest.ResponseRecorder {
+			bodyBytes, _ := json.Marshal(body)
+			req, _ := http.NewRequest("POST", "/v1/rotate", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		transition := func() *httptest.ResponseRecorder {
+			return rotateRequest(map[string]interface{}{
+				"name":       "my-ca",
+				"phase":      "transition",
+				"parameters": map[string]interface{}{"common_name": "my-ca"},
+			})
+		}
+
+		issueLeaf := func() types.CertResponse {
+			value, err := types.NewCertificateGenerator(loader).Generate(map[string]interface{}{"ca": "my-ca", "common_name": "leaf"})
+			Expect(err).ToNot(HaveOccurred())
+			return value.(types.CertResponse)
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			loader = NewX509Loader(memoryStore)
+			handler, _ = NewRotateHandler(memoryStore, types.NewValueGeneratorConcrete(loader, NewSSHKeyLoader(memoryStore), config.GeneratorsConfig{}))
+
+			oldCA = putGeneratedCertificate(memoryStore, "my-ca", map[string]interface{}{"is_ca": true, "common_name": "my-ca"})
+			putGeneratedCertificate(memoryStore, "my-cert", map[string]interface{}{"ca": "my-ca", "common_name": "my-cert"})
+		})
+
+		It("should return 405 Method Not Allowed for anything but POST", func() {
+			req, _ := http.NewRequest("GET", "/v1/rotate", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should return 400 Bad Request for unsupported phases", func() {
+			recorder := rotateRequest(map[string]interface{}{"name": "my-ca", "phase": "restart"})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Unsupported rotation phase 'restart'"))
+		})
+
+		It("should return 400 Bad Request when transition has no parameters", func() {
+			recorder := rotateRequest(map[string]interface{}{"name": "my-ca", "phase": "transition"})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("JSON request body key 'parameters' must be an object for phase 'transition'"))
+		})
+
+		It("should return 404 Not Found for unknown names", func() {
+			recorder := rotateRequest(map[string]interface{}{"name": "unknown", "phase": "promote"})
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return 400 Bad Request when the name is not a CA", func() {
+			recorder := rotateRequest(map[string]interface{}{"name": "my-cert", "phase": "promote"})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Name 'my-cert' is not a CA"))
+		})
+
+		It("lists the signing version of the CA first while it is transitional", func() {
+			recorder := transition()
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+			var newCA map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &newCA)).To(Succeed())
+
+			requestHandler, _ := NewRequestHandler(memoryStore, types.NewValueGeneratorConcrete(loader, NewSSHKeyLoader(memoryStore), config.GeneratorsConfig{}))
+			getByName := func() []map[string]interface{} {
+				req, _ := generateHTTPRequest("GET", "/v1/data?name=my-ca", nil)
+				recorder := httptest.NewRecorder()
+				requestHandler.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				var response struct {
+					Data []map[string]interface{} `json:"data"`
+				}
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				return response.Data
+			}
+
+			data := getByName()
+			Expect(data).To(HaveLen(2))
+			Expect(data[0]["value"]).To(HaveKeyWithValue("certificate", oldCA.Certificate))
+			Expect(data[0]).ToNot(HaveKey("rotation_phase"))
+			Expect(data[1]["id"]).To(Equal(newCA["id"]))
+			Expect(data[1]["rotation_phase"]).To(Equal("transitional"))
+
+			Expect(rotateRequest(map[string]interface{}{"name": "my-ca", "phase": "promote"}).Code).To(Equal(http.StatusOK))
+
+			data = getByName()
+			Expect(data[0]["id"]).To(Equal(newCA["id"]))
+			Expect(data[0]["rotation_phase"]).To(Equal("promoted"))
+		})
+
+		It("should return 409 Conflict when promoting a CA that is not transitional", func() {
+			recorder := rotateRequest(map[string]interface{}{"name": "my-ca", "phase": "promote"})
+
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+			Expect(recorder.Body.String()).To(ContainSubstring("CA 'my-ca' is not in rotation phase 'transitional'"))
+		})
+
+		It("should return 409 Conflict when transitioning a CA twice", func() {
+			Expect(transition().Code).To(Equal(http.StatusCreated))
+
+			recorder := transition()
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+			Expect(recorder.Body.String()).To(ContainSubstring("CA 'my-ca' is already being rotated"))
+		})
+
+		It("should rotate the CA through all phases", func() {
+			recorder := transition()
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+			var response map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response["rotation_phase"]).To(Equal("transitional"))
+			Expect(response["type"]).To(Equal("certificate"))
+			Expect(response["parameters"]).To(HaveKeyWithValue("is_ca", true))
+
+			newCA := response["value"].(map[string]interface{})["certificate"].(string)
+			Expect(newCA).ToNot(Equal(oldCA.Certificate))
+
+			leaf := issueLeaf()
+			Expect(leaf.CA).To(Equal(oldCA.Certificate + newCA))
+			Expect(parseCertificatePEM(leaf.Certificate).CheckSignatureFrom(parseCertificatePEM(oldCA.Certificate))).To(Succeed())
+
+			recorder = rotateRequest(map[string]interface{}{"name": "my-ca", "phase": "promote"})
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"rotation_phase":"promoted"`))
+
+			leaf = issueLeaf()
+			Expect(leaf.CA).To(Equal(newCA + oldCA.Certificate))
+			Expect(parseCertificatePEM(leaf.Certificate).CheckSignatureFrom(parseCertificatePEM(newCA))).To(Succeed())
+
+			recorder = rotateRequest(map[string]interface{}{"name": "my-ca", "phase": "finish"})
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).ToNot(ContainSubstring("rotation_phase"))
+
+			leaf = issueLeaf()
+			Expect(leaf.CA).To(Equal(newCA))
+		})
+
+		It("should make converge reissue existing certificates in every phase", func() {
+			requestHandler, _ := NewRequestHandler(memoryStore, types.NewValueGeneratorConcrete(loader, NewSSHKeyLoader(memoryStore), config.GeneratorsConfig{}))
+
+			converge := func() (int, types.CertResponse) {
+				body := `{"name":"my-leaf","type":"certificate","mode":"converge","parameters":{"ca":"my-ca","common_name":"my-leaf"}}`
+				req, _ := http.NewRequest("POST", "/v1/data", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+
+				recorder := httptest.NewRecorder()
+				requestHandler.ServeHTTP(recorder, req)
+
+				var response struct {
+					Value types.CertResponse `json:"value"`
+				}
+				Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+				return recorder.Code, response.Value
+			}
+
+			code, leaf := converge()
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(leaf.CA).To(Equal(oldCA.Certificate))
+
+			code, _ = converge()
+			Expect(code).To(Equal(http.StatusOK))
+
+			recorder := transition()
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+			var response map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			newCA := response["value"].(map[string]interface{})["certificate"].(string)
+
+			code, leaf = converge()
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(leaf.CA).To(Equal(oldCA.Certificate + newCA))
+			Expect(parseCertificatePEM(leaf.Certificate).CheckSignatureFrom(parseCertificatePEM(oldCA.Certificate))).To(Succeed())
+
+			code, _ = converge()
+			Expect(code).To(Equal(http.StatusOK))
+
+			Expect(rotateRequest(map[string]interface{}{"name": "my-ca", "phase": "promote"}).Code).To(Equal(http.StatusOK))
+
+			code, leaf = converge()
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(leaf.CA).To(Equal(newCA + oldCA.Certificate))
+			Expect(parseCertificatePEM(leaf.Certificate).CheckSignatureFrom(parseCertificatePEM(newCA))).To(Succeed())
+
+			code, _ = converge()
+			Expect(code).To(Equal(http.StatusOK))
+
+			Expect(rotateRequest(map[string]interface{}{"name": "my-ca", "phase": "finish"}).Code).To(Equal(http.StatusOK))
+
+			code, leaf = converge()
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(leaf.CA).To(Equal(newCA))
+
+			code, _ = converge()
+			Expect(code).To(Equal(http.StatusOK))
+		})
+	})
+})
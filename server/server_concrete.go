@@ -3,6 +3,7 @@ package server
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/shono09835/config-server/config"
 	"github.com/shono09835/config-server/store"
@@ -41,14 +42,83 @@ func (cs configServer) configureHandler() error {
 	}
 
 	x509Loader := NewX509Loader(store)
-	requestHandler, err := NewRequestHandler(store, types.NewValueGeneratorConcrete(x509Loader))
+	sshKeyLoader := NewSSHKeyLoader(store)
+	valueGeneratorFactory := types.NewValueGeneratorConcrete(x509Loader, sshKeyLoader, cs.config.Generators)
+	requestHandler, err := NewRequestHandler(store, valueGeneratorFactory)
 	if err != nil {
 		return errors.WrapError(err, "Failed to create Request Handler")
 	}
 	authenticationHandler := NewAuthenticationHandler(jwtTokenValidator, requestHandler)
 
+	totpHandler, err := NewTOTPHandler(store)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create TOTP Handler")
+	}
+
+	signHandler, err := NewSignHandler(store, x509Loader)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create Sign Handler")
+	}
+
+	revokeHandler, err := NewRevokeHandler(store, x509Loader)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create Revoke Handler")
+	}
+
+	crlHandler, err := NewCRLHandler(store, x509Loader, cs.config.CRL)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create CRL Handler")
+	}
+
+	rotateHandler, err := NewRotateHandler(store, valueGeneratorFactory)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create Rotate Handler")
+	}
+
+	certificatesHandler, err := NewCertificatesHandler(store)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create Certificates Handler")
+	}
+
+	regenerateHandler, err := NewRegenerateHandler(store, valueGeneratorFactory)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create Regenerate Handler")
+	}
+
+	dependentsHandler, err := NewDependentsHandler(store)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create Dependents Handler")
+	}
+
+	ocspHandler, err := NewOCSPHandler(store, x509Loader, cs.config.OCSP)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create OCSP Handler")
+	}
+
+	ocspPath := strings.TrimSuffix(cs.config.OCSP.Path, "/")
+	if ocspPath == "" {
+		ocspPath = DefaultOCSPPath
+	}
+
 	http.Handle("/v1/data", authenticationHandler)
 	http.Handle("/v1/data/", authenticationHandler)
+	http.Handle("/v1/data/dependents", NewAuthenticationHandler(jwtTokenValidator, dependentsHandler))
+	http.Handle("/v1/data/regenerate", NewAuthenticationHandler(jwtTokenValidator, regenerateHandler))
+	http.Handle("/v1/totp", NewAuthenticationHandler(jwtTokenValidator, totpHandler))
+	http.Handle("/v1/sign", NewAuthenticationHandler(jwtTokenValidator, signHandler))
+	http.Handle("/v1/revoke", NewAuthenticationHandler(jwtTokenValidator, revokeHandler))
+	http.Handle("/v1/rotate", NewAuthenticationHandler(jwtTokenValidator, rotateHandler))
+	http.Handle("/v1/certificates", NewAuthenticationHandler(jwtTokenValidator, certificatesHandler))
+	http.Handle("/v1/generators", NewAuthenticationHandler(jwtTokenValidator, NewGeneratorsHandler(valueGeneratorFactory.GeneratorDescriptions())))
+	http.Handle("/v1/crl", crlHandler)
+	http.Handle(ocspPath, ocspHandler)
+	http.Handle(ocspPath+"/", ocspHandler)
+
+	certificateRenewer, err := NewCertificateRenewer(store, valueGeneratorFactory, cs.config.Renewal)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create Certificate Renewer")
+	}
+	certificateRenewer.Start()
 
 	return nil
 }
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+type signHandler struct {
+	store  store.Store
+	signer types.CSRSigner
+}
+
+func NewSignHandler(store store.Store, loader types.CertsLoader) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+	return signHandler{
+		store:  store,
+		signer: types.NewCSRSigner(loader),
+	}, nil
+}
+
+func (handler signHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if contentTypeErr := validateRequestContentType(req); contentTypeErr != nil {
+		http.Error(resWriter, NewErrorResponse(contentTypeErr).GenerateErrorMsg(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	name, csr, parameters, err := readSignRequest(req)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	signedCertificate, err := handler.signer.Sign(csr, parameters)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	configuration, err := saveGenerated(handler.store, store.Configuration{Name: name}, signedCertificate, issuingCAName("certificate", parameters))
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	result, _ := configuration.StringifiedJSON()
+	respond(resWriter, result, http.StatusCreated)
+}
+
+func readSignRequest(req *http.Request) (string, string, interface{}, error) {
+	jsonMap, err := readJSONBody(req)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	name, err := getStringValueFromJSONBody(jsonMap, "name")
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if isNameValid, nameError := isValidName(name); !isNameValid {
+		return "", "", nil, nameError
+	}
+
+	csr, err := getStringValueFromJSONBody(jsonMap, "csr")
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return name, csr, jsonMap["parameters"], nil
+}
@@ -0,0 +1,159 @@
+package server_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	. "github.com/shono09835/config-server/store/storefakes"
+	"github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("SignHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewSignHandler(nil, nil)
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var (
+			handler     http.Handler
+			memoryStore store.Store
+			csr         string
+		)
+
+		signRequest := func(body map[string]interface{}) *httptest.ResponseRecorder {
+			bodyBytes, _ := json.Marshal(body)
+			req, _ := http.NewRequest("POST", "/v1/sign", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			handler, _ = NewSignHandler(memoryStore, NewX509Loader(memoryStore))
+
+			ca, err := types.NewCertificateGenerator(nil).Generate(map[string]interface{}{"is_ca": true, "common_name": "test-ca"})
+			Expect(err).ToNot(HaveOccurred())
+
+			caBytes, _ := json.Marshal(map[string]interface{}{"value": ca})
+			_, err = memoryStore.Put("my-ca", string(caBytes), "")
+			Expect(err).ToNot(HaveOccurred())
+
+			key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			csrRaw, _ := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "app.example.com"},
+				DNSNames: []string{"app.example.com"},
+			}, key)
+			csr = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrRaw}))
+		})
+
+		It("should return 405 Method Not Allowed for anything but POST", func() {
+			req, _ := http.NewRequest("GET", "/v1/sign", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should return 415 Unsupported Media Type without a JSON content type", func() {
+			req, _ := http.NewRequest("POST", "/v1/sign", bytes.NewReader([]byte("{}")))
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnsupportedMediaType))
+		})
+
+		It("should return 400 Bad Request when the csr is missing", func() {
+			recorder := signRequest(map[string]interface{}{"name": "my-cert"})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("JSON request body should contain the key 'csr'"))
+		})
+
+		It("should return 400 Bad Request when the name is invalid", func() {
+			recorder := signRequest(map[string]interface{}{"name": "my!cert", "csr": csr})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Name must consist of alphanumeric, underscores, dashes, and forward slashes"))
+		})
+
+		It("should return 400 Bad Request when the CA does not exist", func() {
+			recorder := signRequest(map[string]interface{}{
+				"name":       "my-cert",
+				"csr":        csr,
+				"parameters": map[string]interface{}{"ca": "missing-ca"},
+			})
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("No certificate found"))
+		})
+
+		It("should return 500 Internal Server Error when the store errors", func() {
+			mockStore := &FakeStore{}
+			mockStore.PutGeneratedReturns("", errors.New("fake-error"))
+			handler, _ = NewSignHandler(mockStore, NewX509Loader(memoryStore))
+
+			recorder := signRequest(map[string]interface{}{
+				"name":       "my-cert",
+				"csr":        csr,
+				"parameters": map[string]interface{}{"ca": "my-ca"},
+			})
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("signs the CSR and records the certificate under the name", func() {
+			recorder := signRequest(map[string]interface{}{
+				"name": "my-cert",
+				"csr":  csr,
+				"parameters": map[string]interface{}{
+					"ca":            "my-ca",
+					"allowed_names": []string{"*.example.com"},
+				},
+			})
+
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+			var response struct {
+				ID    string                  `json:"id"`
+				Name  string                  `json:"name"`
+				Value types.SignedCertificate `json:"value"`
+			}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Name).To(Equal("my-cert"))
+
+			block, _ := pem.Decode([]byte(response.Value.Certificate))
+			cert, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cert.DNSNames).To(Equal([]string{"app.example.com"}))
+
+			caCert, _, err := NewX509Loader(memoryStore).LoadCerts("my-ca")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cert.CheckSignatureFrom(caCert)).To(Succeed())
+
+			stored, err := memoryStore.GetByID(response.ID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stored.Name).To(Equal("my-cert"))
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+type sshKeyLoader struct {
+	store store.Store
+}
+
+func NewSSHKeyLoader(store store.Store) types.SSHKeysLoader {
+	return sshKeyLoader{store}
+}
+
+func (l sshKeyLoader) LoadSSHKey(name string) (ssh.Signer, error) {
+	configurations, err := l.store.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(configurations) == 0 {
+		return nil, errors.Error("No SSH key found")
+	}
+
+	var keyContainer struct {
+		SSHKey types.SSHKey `json:"value"`
+	}
+
+	err = json.Unmarshal([]byte(configurations[0].Value), &keyContainer)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to parse SSH key value")
+	}
+
+	if keyContainer.SSHKey.PrivateKey == "" {
+		return nil, errors.Errorf("SSH key %s doesn't contain expected attributes", name)
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(keyContainer.SSHKey.PrivateKey))
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to parse SSH private key")
+	}
+
+	return signer, nil
+}
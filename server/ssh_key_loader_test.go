@@ -0,0 +1,85 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	. "github.com/shono09835/config-server/store/storefakes"
+	"github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("sshKeyLoader", func() {
+	var (
+		loader    types.SSHKeysLoader
+		mockStore *FakeStore
+	)
+
+	BeforeEach(func() {
+		mockStore = &FakeStore{}
+		loader = server.NewSSHKeyLoader(mockStore)
+	})
+
+	Context("when the SSH key is present in the store", func() {
+		var sshKey types.SSHKey
+
+		BeforeEach(func() {
+			generated, err := types.NewSSHKeyGenerator().Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+			sshKey = generated.(types.SSHKey)
+
+			serializedKey, _ := json.Marshal(sshKey)
+			mockStore.GetByNameReturns([]store.Configuration{
+				{Value: `{"value":` + string(serializedKey) + `}`},
+			}, nil)
+		})
+
+		It("returns a signer for the private key", func() {
+			signer, err := loader.LoadSSHKey("some-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockStore.GetByNameArgsForCall(0)).To(Equal("some-name"))
+			Expect(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))).To(Equal(sshKey.PublicKey))
+		})
+	})
+
+	Context("when the SSH key is not present in the store", func() {
+		It("returns an error", func() {
+			mockStore.GetByNameReturns([]store.Configuration{}, nil)
+
+			_, err := loader.LoadSSHKey("some-name")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("No SSH key found"))
+		})
+	})
+
+	Context("when the stored value is not an SSH key", func() {
+		It("returns an error", func() {
+			mockStore.GetByNameReturns([]store.Configuration{{Value: `{"value":"a-password"}`}}, nil)
+
+			_, err := loader.LoadSSHKey("some-name")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error when the private key attribute is missing", func() {
+			mockStore.GetByNameReturns([]store.Configuration{{Value: `{"value":{"public_key":"abc"}}`}}, nil)
+
+			_, err := loader.LoadSSHKey("some-name")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("SSH key some-name doesn't contain expected attributes"))
+		})
+	})
+
+	Context("when the store errors", func() {
+		It("returns the error", func() {
+			mockStore.GetByNameReturns(nil, errors.New("fake-error"))
+
+			_, err := loader.LoadSSHKey("some-name")
+			Expect(err).To(MatchError("fake-error"))
+		})
+	})
+})
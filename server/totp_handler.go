@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/shono09835/config-server/store"
+	"github.com/shono09835/config-server/types"
+)
+
+type totpHandler struct {
+	store store.Store
+}
+
+type totpCodeResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Code      string `json:"code"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+func NewTOTPHandler(store store.Store) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+	return totpHandler{store: store}, nil
+}
+
+func (handler totpHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(resWriter, NewErrorResponse(errors.Error("HTTP method not allowed")).GenerateErrorMsg(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := req.URL.Query().Get("name")
+	if isNameValid, nameError := isValidName(name); !isNameValid {
+		http.Error(resWriter, NewErrorResponse(nameError).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	values, err := handler.store.GetByName(name)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(values) == 0 {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' not found", name)).GenerateErrorMsg(), http.StatusNotFound)
+		return
+	}
+
+	configuration := values[0]
+
+	var seedContainer struct {
+		Seed types.TOTPSeed `json:"value"`
+	}
+
+	err = json.Unmarshal([]byte(configuration.Value), &seedContainer)
+	if err != nil || seedContainer.Seed.Seed == "" {
+		http.Error(resWriter, NewErrorResponse(errors.Errorf("Name '%s' is not a TOTP seed", name)).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	seed := seedContainer.Seed
+	now := time.Now()
+
+	code, err := seed.Code(now)
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := json.Marshal(totpCodeResponse{
+		ID:        configuration.ID,
+		Name:      configuration.Name,
+		Code:      code,
+		ExpiresIn: seed.Period - int(now.Unix()%int64(seed.Period)),
+	})
+	if err != nil {
+		http.Error(resWriter, NewErrorResponse(err).GenerateErrorMsg(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, string(result), http.StatusOK)
+}
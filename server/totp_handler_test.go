@@ -0,0 +1,119 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/server"
+	"github.com/shono09835/config-server/store"
+	. "github.com/shono09835/config-server/store/storefakes"
+	"github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("TOTPHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewTOTPHandler(nil)
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var (
+			handler   http.Handler
+			mockStore *FakeStore
+		)
+
+		BeforeEach(func() {
+			mockStore = &FakeStore{}
+			handler, _ = NewTOTPHandler(mockStore)
+		})
+
+		It("should return 405 Method Not Allowed for anything but GET", func() {
+			req, _ := http.NewRequest("POST", "/v1/totp?name=smurf", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should return 400 Bad Request when name is invalid", func() {
+			req, _ := http.NewRequest("GET", "/v1/totp?name=sm!urf", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Name must consist of alphanumeric, underscores, dashes, and forward slashes"))
+		})
+
+		It("should return 404 Not Found when name does not exist", func() {
+			req, _ := http.NewRequest("GET", "/v1/totp?name=smurf", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(ContainSubstring("Name 'smurf' not found"))
+		})
+
+		It("should return 500 Internal Server Error when store errors", func() {
+			mockStore.GetByNameReturns(nil, errors.New("Unknown error"))
+
+			req, _ := http.NewRequest("GET", "/v1/totp?name=smurf", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("should return 400 Bad Request when value is not a TOTP seed", func() {
+			mockStore.GetByNameReturns(store.Configurations{
+				{ID: "1", Name: "smurf", Value: `{"value":"blue"}`},
+			}, nil)
+
+			req, _ := http.NewRequest("GET", "/v1/totp?name=smurf", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(Equal(`{"error":"Name 'smurf' is not a TOTP seed"}` + "\n"))
+		})
+
+		It("returns the current code of the latest seed", func() {
+			memoryStore := store.NewMemoryStore()
+			handler, _ = NewTOTPHandler(memoryStore)
+
+			seed, err := types.NewTOTPGenerator().Generate(map[interface{}]interface{}{"account": "admin"})
+			Expect(err).ToNot(HaveOccurred())
+
+			bytes, _ := json.Marshal(map[string]interface{}{"value": seed})
+			id, _ := memoryStore.Put("smurf", string(bytes), "")
+
+			before := time.Now()
+			req, _ := http.NewRequest("GET", "/v1/totp?name=smurf", nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			after := time.Now()
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var data map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &data)).To(Succeed())
+
+			codeBefore, _ := seed.(types.TOTPSeed).Code(before)
+			codeAfter, _ := seed.(types.TOTPSeed).Code(after)
+
+			Expect(data["id"]).To(Equal(id))
+			Expect(data["name"]).To(Equal("smurf"))
+			Expect(data["code"]).To(BeElementOf(codeBefore, codeAfter))
+			Expect(data["expires_in"]).To(BeNumerically(">", 0))
+			Expect(data["expires_in"]).To(BeNumerically("<=", 30))
+		})
+	})
+})
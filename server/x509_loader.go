@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"strings"
 
 	"github.com/cloudfoundry/bosh-utils/errors"
 
@@ -21,39 +22,30 @@ func NewX509Loader(store store.Store) types.CertsLoader {
 }
 
 func (l x509Loader) LoadCerts(name string) (*x509.Certificate, *rsa.PrivateKey, error) {
-
-	configurations, err := l.store.GetByName(name)
+	certValue, err := l.loadCertResponse(name)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if len(configurations) == 0 {
-		return nil, nil, errors.Error("No certificate found")
-	}
-
-	configuration := configurations[0]
-
-	var certContainer struct {
-		CertResponse types.CertResponse `json:"value"`
-	}
-
-	err = json.Unmarshal([]byte(configuration.Value), &certContainer)
-	if err != nil {
-		return nil, nil, errors.WrapError(err, "Failed to parse certificate value")
-	}
-	certValue := certContainer.CertResponse
-
 	if certValue.Certificate == "" || certValue.PrivateKey == "" {
 		return nil, nil, errors.Errorf("Certificate %s doesn't contain expected attributes\n", name)
 	}
 
 	cpb, _ := pem.Decode([]byte(certValue.Certificate))
+	if cpb == nil {
+		return nil, nil, errors.Errorf("Certificate %s is not PEM encoded", name)
+	}
+
 	rootCrt, err := x509.ParseCertificate(cpb.Bytes)
 	if err != nil {
 		return nil, nil, errors.WrapError(err, "Failed to parse root certificate")
 	}
 
 	kpb, _ := pem.Decode([]byte(certValue.PrivateKey))
+	if kpb == nil {
+		return nil, nil, errors.Errorf("Private key of certificate %s is not PEM encoded", name)
+	}
+
 	rootKey, err := x509.ParsePKCS1PrivateKey(kpb.Bytes)
 	if err != nil {
 		return nil, nil, errors.WrapError(err, "Failed to parse root private key")
@@ -61,3 +53,99 @@ func (l x509Loader) LoadCerts(name string) (*x509.Certificate, *rsa.PrivateKey,
 
 	return rootCrt, rootKey, nil
 }
+
+// LoadChain returns the PEM encoded chain for the named CA, starting with the
+// CA certificate itself and ending with the root. Certificates generated
+// before chains were tracked fall back to their 'ca' value.
+func (l x509Loader) LoadChain(name string) (string, error) {
+	certValue, err := l.loadCertResponse(name)
+	if err != nil {
+		return "", err
+	}
+
+	if certValue.Certificate == "" {
+		return "", errors.Errorf("Certificate %s doesn't contain expected attributes\n", name)
+	}
+
+	issuers := certValue.Chain
+	if issuers == "" {
+		issuers = certValue.CA
+	}
+
+	if issuers == "" || issuers == certValue.Certificate {
+		return certValue.Certificate, nil
+	}
+
+	return joinPEM(certValue.Certificate, issuers), nil
+}
+
+// LoadCABundle returns the certificates of the CA versions that are trusted
+// while the named CA is rotated, or just its certificate otherwise.
+func (l x509Loader) LoadCABundle(name string) (string, error) {
+	configurations, err := l.loadVersions(name)
+	if err != nil {
+		return "", err
+	}
+
+	var certificates []string
+	for _, configuration := range trustedVersions(configurations) {
+		certValue, err := parseCertResponse(configuration)
+		if err != nil {
+			return "", err
+		}
+
+		if certValue.Certificate == "" {
+			return "", errors.Errorf("Certificate %s doesn't contain expected attributes\n", name)
+		}
+
+		certificates = append(certificates, certValue.Certificate)
+	}
+
+	return joinPEM(certificates...), nil
+}
+
+func (l x509Loader) loadCertResponse(name string) (types.CertResponse, error) {
+	configurations, err := l.loadVersions(name)
+	if err != nil {
+		return types.CertResponse{}, err
+	}
+
+	return parseCertResponse(signingVersion(configurations))
+}
+
+func (l x509Loader) loadVersions(name string) (store.Configurations, error) {
+	configurations, err := l.store.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(configurations) == 0 {
+		return nil, errors.Error("No certificate found")
+	}
+
+	return configurations, nil
+}
+
+func parseCertResponse(configuration store.Configuration) (types.CertResponse, error) {
+	var certContainer struct {
+		CertResponse types.CertResponse `json:"value"`
+	}
+
+	err := json.Unmarshal([]byte(configuration.Value), &certContainer)
+	if err != nil {
+		return types.CertResponse{}, errors.WrapError(err, "Failed to parse certificate value")
+	}
+
+	return certContainer.CertResponse, nil
+}
+
+func joinPEM(blocks ...string) string {
+	var joined strings.Builder
+	for _, block := range blocks {
+		joined.WriteString(block)
+		if !strings.HasSuffix(block, "\n") {
+			joined.WriteString("\n")
+		}
+	}
+	return joined.String()
+}
@@ -147,4 +147,127 @@ sHx2rlaLkmSreYJsmVaiSp0E9lhdympuDF+WKRolkQ==
 		})
 	})
 
+	Context("when the certificate is not PEM encoded", func() {
+		respValues := []store.Configuration{
+			{
+				Value: `{"value":{"certificate":"not-pem", "private_key": "some-private-key"}}`,
+			},
+		}
+		BeforeEach(func() {
+			mockStore.GetByNameReturns(respValues, nil)
+		})
+		It("it should throw an error", func() {
+			_, _, err := loader.LoadCerts("some-name")
+
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("Certificate some-name is not PEM encoded"))
+		})
+	})
+
+	Describe("LoadChain", func() {
+		It("returns the certificate followed by its stored chain", func() {
+			mockStore.GetByNameReturns([]store.Configuration{
+				{Value: `{"value":{"certificate":"intermediate\n","private_key":"key","ca":"root\n","chain":"root\n"}}`},
+			}, nil)
+
+			chain, err := loader.LoadChain("some-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chain).To(Equal("intermediate\nroot\n"))
+		})
+
+		It("returns only the certificate for self-signed CAs", func() {
+			mockStore.GetByNameReturns([]store.Configuration{
+				{Value: `{"value":{"certificate":"root\n","private_key":"key","ca":"root\n","chain":"root\n"}}`},
+			}, nil)
+
+			chain, err := loader.LoadChain("some-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chain).To(Equal("root\n"))
+		})
+
+		It("falls back to the ca value when no chain is stored", func() {
+			mockStore.GetByNameReturns([]store.Configuration{
+				{Value: `{"value":{"certificate":"intermediate","private_key":"key","ca":"root"}}`},
+			}, nil)
+
+			chain, err := loader.LoadChain("some-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chain).To(Equal("intermediate\nroot\n"))
+		})
+
+		It("returns an error when the certificate is not present", func() {
+			mockStore.GetByNameReturns([]store.Configuration{}, nil)
+
+			_, err := loader.LoadChain("some-name")
+			Expect(err).To(MatchError("No certificate found"))
+		})
+
+		It("builds the full chain for certificates issued by intermediate CAs", func() {
+			memoryStore := store.NewMemoryStore()
+			loader = server.NewX509Loader(memoryStore)
+			generator := types.NewCertificateGenerator(loader)
+
+			generate := func(name string, params map[string]interface{}) types.CertResponse {
+				value, err := generator.Generate(params)
+				Expect(err).ToNot(HaveOccurred())
+
+				bytes, _ := json.Marshal(map[string]interface{}{"value": value})
+				_, err = memoryStore.Put(name, string(bytes), "")
+				Expect(err).ToNot(HaveOccurred())
+
+				return value.(types.CertResponse)
+			}
+
+			root := generate("root", map[string]interface{}{"is_ca": true, "common_name": "root"})
+			intermediate := generate("intermediate", map[string]interface{}{"is_ca": true, "common_name": "intermediate", "ca": "root"})
+			leaf := generate("leaf", map[string]interface{}{"common_name": "leaf", "ca": "intermediate"})
+
+			Expect(root.Chain).To(Equal(root.Certificate))
+			Expect(intermediate.CA).To(Equal(root.Certificate))
+			Expect(intermediate.Chain).To(Equal(root.Certificate))
+			Expect(leaf.CA).To(Equal(intermediate.Certificate))
+			Expect(leaf.Chain).To(Equal(intermediate.Certificate + root.Certificate))
+		})
+	})
+	Describe("LoadCABundle", func() {
+		It("returns the certificate of the CA when it is not rotated", func() {
+			mockStore.GetByNameReturns([]store.Configuration{
+				{Value: `{"value":{"certificate":"new\n","private_key":"key"}}`},
+				{Value: `{"value":{"certificate":"old\n","private_key":"key"}}`},
+			}, nil)
+
+			bundle, err := loader.LoadCABundle("some-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bundle).To(Equal("new\n"))
+		})
+
+		It("returns the previous certificate followed by the transitional one", func() {
+			mockStore.GetByNameReturns([]store.Configuration{
+				{Value: `{"value":{"certificate":"new\n","private_key":"key"}}`, RotationPhase: server.RotationPhaseTransitional},
+				{Value: `{"value":{"certificate":"old\n","private_key":"key"}}`},
+			}, nil)
+
+			bundle, err := loader.LoadCABundle("some-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bundle).To(Equal("old\nnew\n"))
+		})
+
+		It("returns the promoted certificate followed by the previous one", func() {
+			mockStore.GetByNameReturns([]store.Configuration{
+				{Value: `{"value":{"certificate":"new\n","private_key":"key"}}`, RotationPhase: server.RotationPhasePromoted},
+				{Value: `{"value":{"certificate":"old\n","private_key":"key"}}`},
+			}, nil)
+
+			bundle, err := loader.LoadCABundle("some-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bundle).To(Equal("new\nold\n"))
+		})
+
+		It("returns an error when the certificate is not present", func() {
+			mockStore.GetByNameReturns([]store.Configuration{}, nil)
+
+			_, err := loader.LoadCABundle("some-name")
+			Expect(err).To(MatchError("No certificate found"))
+		})
+	})
 })
@@ -9,6 +9,9 @@ type Configuration struct {
 	Name              string
 	Value             string
 	ParameterChecksum string
+	RotationPhase     string
+	GeneratorType     string
+	Parameters        string
 }
 
 func (rv Configuration) StringifiedJSON() (string, error) {
@@ -18,6 +21,15 @@ func (rv Configuration) StringifiedJSON() (string, error) {
 
 	val["id"] = rv.ID
 	val["name"] = rv.Name
+	if rv.RotationPhase != "" {
+		val["rotation_phase"] = rv.RotationPhase
+	}
+	if rv.GeneratorType != "" {
+		val["type"] = rv.GeneratorType
+	}
+	if rv.Parameters != "" {
+		val["parameters"] = json.RawMessage(rv.Parameters)
+	}
 	bytes, err := json.Marshal(&val)
 
 	return string(bytes), err
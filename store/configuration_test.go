@@ -38,6 +38,37 @@ var _ = Describe("Configuration", func() {
 			})
 		})
 
+		Context("When the configuration has a rotation phase", func() {
+			It("includes the phase in the json string", func() {
+				configuration := store.Configuration{
+					ID:            "123",
+					Name:          "smurf",
+					Value:         `{"value": "blue"}`,
+					RotationPhase: "transitional",
+				}
+
+				jsonString, _ := configuration.StringifiedJSON()
+
+				Expect(jsonString).To(Equal(`{"id":"123","name":"smurf","rotation_phase":"transitional","value":"blue"}`))
+			})
+		})
+
+		Context("When the configuration was generated", func() {
+			It("includes the generator type and parameters in the json string", func() {
+				configuration := store.Configuration{
+					ID:            "123",
+					Name:          "smurf",
+					Value:         `{"value": "blue"}`,
+					GeneratorType: "password",
+					Parameters:    `{"length":30}`,
+				}
+
+				jsonString, _ := configuration.StringifiedJSON()
+
+				Expect(jsonString).To(Equal(`{"id":"123","name":"smurf","parameters":{"length":30},"type":"password","value":"blue"}`))
+			})
+		})
+
 		Context("When value is complex", func() {
 			It("returns json string from the given db result", func() {
 				configuration := store.Configuration{
@@ -3,6 +3,13 @@ package db_migrations
 func PostgresMigrations() []string {
 	migrations := []string{
 		"CREATE TABLE configurations (id SERIAL NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, value TEXT NOT NULL, checksum TEXT NOT NULL DEFAULT '')",
+		"CREATE TABLE revocations (id SERIAL NOT NULL PRIMARY KEY, ca_name VARCHAR(255) NOT NULL, serial_number VARCHAR(64) NOT NULL, reason INT NOT NULL DEFAULT 0, revoked_at BIGINT NOT NULL, UNIQUE (ca_name, serial_number))",
+		"CREATE TABLE issued_certificates (id SERIAL NOT NULL PRIMARY KEY, ca_name VARCHAR(255) NOT NULL, serial_number VARCHAR(64) NOT NULL UNIQUE, name VARCHAR(255) NOT NULL, configuration_id VARCHAR(64) NOT NULL)",
+		"ALTER TABLE configurations ADD COLUMN rotation_phase VARCHAR(32) NOT NULL DEFAULT ''",
+		"CREATE TABLE renewals (id SERIAL NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, previous_id VARCHAR(64) NOT NULL, configuration_id VARCHAR(64) NOT NULL DEFAULT '', expires_at BIGINT NOT NULL, renewed_at BIGINT NOT NULL, dry_run BOOLEAN NOT NULL DEFAULT FALSE)",
+		"ALTER TABLE issued_certificates ADD COLUMN ca_configuration_id VARCHAR(64) NOT NULL DEFAULT ''",
+		"ALTER TABLE configurations ADD COLUMN generator_type VARCHAR(255) NOT NULL DEFAULT ''",
+		"ALTER TABLE configurations ADD COLUMN parameters TEXT NOT NULL DEFAULT ''",
 	}
 
 	return migrations
@@ -11,6 +18,13 @@ func PostgresMigrations() []string {
 func MysqlMigrations() []string {
 	migrations := []string{
 		"CREATE TABLE configurations (id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, name VARCHAR(255) NOT NULL, value TEXT NOT NULL, checksum TEXT NOT NULL)",
+		"CREATE TABLE revocations (id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, ca_name VARCHAR(255) NOT NULL, serial_number VARCHAR(64) NOT NULL, reason INT NOT NULL DEFAULT 0, revoked_at BIGINT NOT NULL, UNIQUE (ca_name, serial_number))",
+		"CREATE TABLE issued_certificates (id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, ca_name VARCHAR(255) NOT NULL, serial_number VARCHAR(64) NOT NULL UNIQUE, name VARCHAR(255) NOT NULL, configuration_id VARCHAR(64) NOT NULL)",
+		"ALTER TABLE configurations ADD COLUMN rotation_phase VARCHAR(32) NOT NULL DEFAULT ''",
+		"CREATE TABLE renewals (id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, name VARCHAR(255) NOT NULL, previous_id VARCHAR(64) NOT NULL, configuration_id VARCHAR(64) NOT NULL DEFAULT '', expires_at BIGINT NOT NULL, renewed_at BIGINT NOT NULL, dry_run BOOLEAN NOT NULL DEFAULT FALSE)",
+		"ALTER TABLE issued_certificates ADD COLUMN ca_configuration_id VARCHAR(64) NOT NULL DEFAULT ''",
+		"ALTER TABLE configurations ADD COLUMN generator_type VARCHAR(255) NOT NULL DEFAULT ''",
+		"ALTER TABLE configurations ADD COLUMN parameters TEXT NOT NULL",
 	}
 
 	return migrations
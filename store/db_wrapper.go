@@ -25,6 +25,14 @@ func (w DBWrapper) QueryRow(query string, args ...interface{}) IRow {
 	return NewRowWrapper(w.db.QueryRow(query, args...))
 }
 
+func (w DBWrapper) Begin() (ITx, error) {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return NewTxWrapper(tx), nil
+}
+
 func (w DBWrapper) Close() {
 	w.db.Close()
 }
@@ -0,0 +1,11 @@
+package store
+
+type IssuedCertificate struct {
+	CAName            string
+	CAConfigurationID string
+	SerialNumber      string
+	Name              string
+	ConfigurationID   string
+}
+
+type IssuedCertificates []IssuedCertificate
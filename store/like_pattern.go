@@ -0,0 +1,11 @@
+package store
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likePrefixPattern returns a LIKE pattern matching names starting with
+// prefix, escaping the wildcards it may contain.
+func likePrefixPattern(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
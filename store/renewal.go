@@ -0,0 +1,14 @@
+package store
+
+import "time"
+
+type Renewal struct {
+	Name            string
+	PreviousID      string
+	ConfigurationID string
+	ExpiresAt       time.Time
+	RenewedAt       time.Time
+	DryRun          bool
+}
+
+type Renewals []Renewal
@@ -0,0 +1,12 @@
+package store
+
+import "time"
+
+type Revocation struct {
+	CAName       string
+	SerialNumber string
+	Reason       int
+	RevokedAt    time.Time
+}
+
+type Revocations []Revocation
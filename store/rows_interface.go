@@ -5,5 +5,6 @@ package store
 type IRows interface {
 	Next() bool
 	Close() error
+	Err() error
 	Scan(dest ...interface{}) error
 }
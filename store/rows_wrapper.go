@@ -23,3 +23,7 @@ func (w RowsWrapper) Scan(dest ...interface{}) error {
 func (w RowsWrapper) Close() error {
 	return w.rows.Close()
 }
+
+func (w RowsWrapper) Err() error {
+	return w.rows.Err()
+}
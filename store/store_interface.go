@@ -6,5 +6,15 @@ type Store interface {
 	Put(key string, value string, checksum string) (string, error)
 	GetByName(name string) (Configurations, error)
 	GetByID(id string) (Configuration, error)
+	GetLatestByNamePrefix(prefix string) (Configurations, error)
 	Delete(key string) (int, error)
+	SetRotationPhase(id string, phase string) error
+	PutGenerated(configuration Configuration, issuedCertificates IssuedCertificates) (string, error)
+	PutRevocation(revocation Revocation) error
+	GetRevocations(caName string) (Revocations, error)
+	PutIssuedCertificate(issuedCertificate IssuedCertificate) error
+	GetIssuedCertificate(serialNumber string) (IssuedCertificate, error)
+	GetIssuedCertificates(caName string) (IssuedCertificates, error)
+	PutRenewal(renewal Renewal) error
+	GetRenewals(name string) (Renewals, error)
 }
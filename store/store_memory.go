@@ -3,17 +3,26 @@ package store
 import (
 	"sort"
 	"strconv"
+	"strings"
 )
 
 type MemoryStore struct {
-	db map[string]Configuration
+	db                 map[string]Configuration
+	revocations        map[string]Revocations
+	issuedCertificates map[string]IssuedCertificate
+	renewals           map[string]Renewals
 }
 
 var dbCounter int
 
 func NewMemoryStore() Store {
 	dbCounter = 0
-	return MemoryStore{db: make(map[string]Configuration)}
+	return MemoryStore{
+		db:                 make(map[string]Configuration),
+		revocations:        make(map[string]Revocations),
+		issuedCertificates: make(map[string]IssuedCertificate),
+		renewals:           make(map[string]Renewals),
+	}
 }
 
 func (store MemoryStore) Put(name string, value string, checksum string) (string, error) {
@@ -47,6 +56,34 @@ func (store MemoryStore) GetByID(id string) (Configuration, error) {
 	return store.db[id], nil
 }
 
+func (store MemoryStore) GetLatestByNamePrefix(prefix string) (Configurations, error) {
+	latest := map[string]Configuration{}
+
+	for _, config := range store.db {
+		if !strings.HasPrefix(config.Name, prefix) {
+			continue
+		}
+
+		if current, ok := latest[config.Name]; ok {
+			currentID, _ := strconv.Atoi(current.ID)
+			configID, _ := strconv.Atoi(config.ID)
+			if currentID > configID {
+				continue
+			}
+		}
+		latest[config.Name] = config
+	}
+
+	var results Configurations
+	for _, config := range latest {
+		results = append(results, config)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	return results, nil
+}
+
 func (store MemoryStore) Delete(name string) (int, error) {
 	deletedCount := 0
 
@@ -59,3 +96,70 @@ func (store MemoryStore) Delete(name string) (int, error) {
 
 	return deletedCount, nil
 }
+
+func (store MemoryStore) SetRotationPhase(id string, phase string) error {
+	if config, ok := store.db[id]; ok {
+		config.RotationPhase = phase
+		store.db[id] = config
+	}
+	return nil
+}
+
+func (store MemoryStore) PutGenerated(configuration Configuration, issuedCertificates IssuedCertificates) (string, error) {
+	configuration.ID = strconv.Itoa(dbCounter)
+	dbCounter++
+
+	store.db[configuration.ID] = configuration
+
+	for _, issuedCertificate := range issuedCertificates {
+		issuedCertificate.ConfigurationID = configuration.ID
+		store.issuedCertificates[issuedCertificate.SerialNumber] = issuedCertificate
+	}
+
+	return configuration.ID, nil
+}
+
+func (store MemoryStore) PutRevocation(revocation Revocation) error {
+	store.revocations[revocation.CAName] = append(store.revocations[revocation.CAName], revocation)
+	return nil
+}
+
+func (store MemoryStore) GetRevocations(caName string) (Revocations, error) {
+	return store.revocations[caName], nil
+}
+
+func (store MemoryStore) PutIssuedCertificate(issuedCertificate IssuedCertificate) error {
+	store.issuedCertificates[issuedCertificate.SerialNumber] = issuedCertificate
+	return nil
+}
+
+func (store MemoryStore) GetIssuedCertificate(serialNumber string) (IssuedCertificate, error) {
+	return store.issuedCertificates[serialNumber], nil
+}
+
+func (store MemoryStore) GetIssuedCertificates(caName string) (IssuedCertificates, error) {
+	var results IssuedCertificates
+
+	for _, issuedCertificate := range store.issuedCertificates {
+		if issuedCertificate.CAName == caName {
+			results = append(results, issuedCertificate)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		left, _ := strconv.Atoi(results[i].ConfigurationID)
+		right, _ := strconv.Atoi(results[j].ConfigurationID)
+		return left < right
+	})
+
+	return results, nil
+}
+
+func (store MemoryStore) PutRenewal(renewal Renewal) error {
+	store.renewals[renewal.Name] = append(store.renewals[renewal.Name], renewal)
+	return nil
+}
+
+func (store MemoryStore) GetRenewals(name string) (Renewals, error) {
+	return store.renewals[name], nil
+}
@@ -1,6 +1,8 @@
 package store_test
 
 import (
+	"time"
+
 	. "github.com/shono09835/config-server/store"
 
 	. "github.com/onsi/ginkgo"
@@ -141,5 +143,138 @@ var _ = Describe("StoreMemory", func() {
 				})
 			})
 		})
+
+		Context("GetLatestByNamePrefix", func() {
+			It("returns the latest version of every name starting with the prefix, sorted by name", func() {
+				store.Put("/team/b", "b1", "")  //nolint:errcheck
+				store.Put("/team/a", "a1", "")  //nolint:errcheck
+				store.Put("/team/b", "b2", "")  //nolint:errcheck
+				store.Put("/other/c", "c1", "") //nolint:errcheck
+
+				values, err := store.GetLatestByNamePrefix("/team/")
+				Expect(err).To(BeNil())
+				Expect(values).To(Equal(Configurations{
+					{ID: "1", Name: "/team/a", Value: "a1"},
+					{ID: "2", Name: "/team/b", Value: "b2"},
+				}))
+			})
+
+			It("returns every name for an empty prefix", func() {
+				store.Put("b", "b1", "") //nolint:errcheck
+				store.Put("a", "a1", "") //nolint:errcheck
+
+				values, err := store.GetLatestByNamePrefix("")
+				Expect(err).To(BeNil())
+				Expect(len(values)).To(Equal(2))
+			})
+		})
+
+		Context("SetRotationPhase", func() {
+			It("updates the rotation phase of the configuration", func() {
+				id, _ := store.Put("ca", "value", "")
+
+				Expect(store.SetRotationPhase(id, "transitional")).To(Succeed())
+
+				configuration, err := store.GetByID(id)
+				Expect(err).To(BeNil())
+				Expect(configuration.RotationPhase).To(Equal("transitional"))
+			})
+		})
+
+		Context("PutGenerated", func() {
+			It("stores the configuration with its provenance and the certificates it holds", func() {
+				id, err := store.PutGenerated(Configuration{
+					Name:              "cert",
+					Value:             "value",
+					ParameterChecksum: "checksum",
+					RotationPhase:     "transitional",
+					GeneratorType:     "certificate",
+					Parameters:        `{"ca":"my-ca"}`,
+				}, IssuedCertificates{{CAName: "my-ca", CAConfigurationID: "1", SerialNumber: "abc", Name: "cert"}})
+				Expect(err).To(BeNil())
+
+				configuration, err := store.GetByID(id)
+				Expect(err).To(BeNil())
+				Expect(configuration).To(Equal(Configuration{
+					ID:                id,
+					Name:              "cert",
+					Value:             "value",
+					ParameterChecksum: "checksum",
+					RotationPhase:     "transitional",
+					GeneratorType:     "certificate",
+					Parameters:        `{"ca":"my-ca"}`,
+				}))
+
+				issuedCertificate, err := store.GetIssuedCertificate("abc")
+				Expect(err).To(BeNil())
+				Expect(issuedCertificate).To(Equal(IssuedCertificate{CAName: "my-ca", CAConfigurationID: "1", SerialNumber: "abc", Name: "cert", ConfigurationID: id}))
+			})
+		})
+
+		Context("Revocations", func() {
+			It("returns the revocations recorded for a CA", func() {
+				revokedAt := time.Unix(1500000000, 0).UTC()
+
+				Expect(store.PutRevocation(Revocation{CAName: "ca-1", SerialNumber: "abc", Reason: 1, RevokedAt: revokedAt})).To(Succeed())
+				Expect(store.PutRevocation(Revocation{CAName: "ca-2", SerialNumber: "def"})).To(Succeed())
+
+				revocations, err := store.GetRevocations("ca-1")
+				Expect(err).To(BeNil())
+				Expect(revocations).To(Equal(Revocations{
+					{CAName: "ca-1", SerialNumber: "abc", Reason: 1, RevokedAt: revokedAt},
+				}))
+			})
+
+			It("returns no revocations for unknown CAs", func() {
+				revocations, err := store.GetRevocations("unknown")
+				Expect(err).To(BeNil())
+				Expect(revocations).To(BeEmpty())
+			})
+		})
+
+		Context("Issued certificates", func() {
+			It("returns the certificate recorded for a serial number", func() {
+				issuedCertificate := IssuedCertificate{CAName: "ca", SerialNumber: "abc", Name: "cert", ConfigurationID: "1"}
+				Expect(store.PutIssuedCertificate(issuedCertificate)).To(Succeed())
+
+				result, err := store.GetIssuedCertificate("abc")
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(issuedCertificate))
+			})
+
+			It("returns an empty result for unknown serial numbers", func() {
+				result, err := store.GetIssuedCertificate("unknown")
+				Expect(err).To(BeNil())
+				Expect(result).To(Equal(IssuedCertificate{}))
+			})
+
+			It("returns the certificates issued by a CA ordered by configuration", func() {
+				Expect(store.PutIssuedCertificate(IssuedCertificate{CAName: "ca", CAConfigurationID: "0", SerialNumber: "c", Name: "cert-2", ConfigurationID: "10"})).To(Succeed())
+				Expect(store.PutIssuedCertificate(IssuedCertificate{CAName: "ca", CAConfigurationID: "0", SerialNumber: "a", Name: "cert-1", ConfigurationID: "9"})).To(Succeed())
+				Expect(store.PutIssuedCertificate(IssuedCertificate{CAName: "other-ca", SerialNumber: "b", Name: "cert-3", ConfigurationID: "11"})).To(Succeed())
+
+				results, err := store.GetIssuedCertificates("ca")
+				Expect(err).To(BeNil())
+				Expect(results).To(Equal(IssuedCertificates{
+					{CAName: "ca", CAConfigurationID: "0", SerialNumber: "a", Name: "cert-1", ConfigurationID: "9"},
+					{CAName: "ca", CAConfigurationID: "0", SerialNumber: "c", Name: "cert-2", ConfigurationID: "10"},
+				}))
+			})
+		})
+
+		Context("Renewals", func() {
+			It("returns the renewals recorded for a name", func() {
+				renewedAt := time.Unix(1500000000, 0).UTC()
+
+				Expect(store.PutRenewal(Renewal{Name: "cert-1", PreviousID: "1", ConfigurationID: "2", RenewedAt: renewedAt})).To(Succeed())
+				Expect(store.PutRenewal(Renewal{Name: "cert-2", PreviousID: "3", DryRun: true})).To(Succeed())
+
+				renewals, err := store.GetRenewals("cert-1")
+				Expect(err).To(BeNil())
+				Expect(renewals).To(Equal(Renewals{
+					{Name: "cert-1", PreviousID: "1", ConfigurationID: "2", RenewedAt: renewedAt},
+				}))
+			})
+		})
 	})
 })
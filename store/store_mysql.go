@@ -3,6 +3,7 @@ package store
 import (
 	"database/sql"
 	"strconv"
+	"time"
 )
 
 type mysqlStore struct {
@@ -20,7 +21,7 @@ func (ms mysqlStore) Put(name string, value string, checksum string) (string, er
 	}
 
 	result, err := //nolint:ineffassign,staticcheck
-		db.Exec("INSERT INTO configurations (name, value, checksum) VALUES(?,?,?)", name, value, checksum)
+		db.Exec("INSERT INTO configurations (name, value, checksum, parameters) VALUES(?,?,?,'')", name, value, checksum)
 
 	id, err := result.LastInsertId()
 	if err != nil {
@@ -38,7 +39,7 @@ func (ms mysqlStore) GetByName(name string) (Configurations, error) {
 		return results, err
 	}
 
-	rows, err := db.Query("SELECT id, name, value, checksum FROM configurations WHERE name = ? ORDER BY id DESC", name)
+	rows, err := db.Query("SELECT id, name, value, checksum, rotation_phase, generator_type, parameters FROM configurations WHERE name = ? ORDER BY id DESC", name)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return results, nil
@@ -50,7 +51,7 @@ func (ms mysqlStore) GetByName(name string) (Configurations, error) {
 
 	for rows.Next() {
 		var config Configuration
-		if err := rows.Scan(&config.ID, &config.Name, &config.Value, &config.ParameterChecksum); err != nil {
+		if err := rows.Scan(&config.ID, &config.Name, &config.Value, &config.ParameterChecksum, &config.RotationPhase, &config.GeneratorType, &config.Parameters); err != nil {
 			return results, err
 		}
 		results = append(results, config)
@@ -67,7 +68,7 @@ func (ms mysqlStore) GetByID(id string) (Configuration, error) {
 		return result, err
 	}
 
-	err = db.QueryRow("SELECT id, name, value FROM configurations WHERE id = ?", id).Scan(&result.ID, &result.Name, &result.Value)
+	err = db.QueryRow("SELECT id, name, value, rotation_phase, generator_type, parameters FROM configurations WHERE id = ?", id).Scan(&result.ID, &result.Name, &result.Value, &result.RotationPhase, &result.GeneratorType, &result.Parameters)
 	if err == sql.ErrNoRows {
 		return result, nil
 	}
@@ -75,6 +76,35 @@ func (ms mysqlStore) GetByID(id string) (Configuration, error) {
 	return result, err
 }
 
+func (ms mysqlStore) GetLatestByNamePrefix(prefix string) (Configurations, error) {
+	var results Configurations
+
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query("SELECT id, name, value, checksum, rotation_phase, generator_type, parameters FROM configurations WHERE id IN (SELECT MAX(id) FROM configurations WHERE name LIKE ? GROUP BY name) ORDER BY name", likePrefixPattern(prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var config Configuration
+		if err := rows.Scan(&config.ID, &config.Name, &config.Value, &config.ParameterChecksum, &config.RotationPhase, &config.GeneratorType, &config.Parameters); err != nil {
+			return results, err
+		}
+		results = append(results, config)
+	}
+
+	return results, rows.Err()
+}
+
 func (ms mysqlStore) Delete(name string) (int, error) {
 	deletedCount := 0
 
@@ -95,3 +125,198 @@ func (ms mysqlStore) Delete(name string) (int, error) {
 
 	return 0, err
 }
+
+func (ms mysqlStore) SetRotationPhase(id string, phase string) error {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("UPDATE configurations SET rotation_phase = ? WHERE id = ?", phase, id)
+	return err
+}
+
+// PutGenerated stores a generated configuration together with the records
+// of the certificates it holds, in a single transaction.
+func (ms mysqlStore) PutGenerated(configuration Configuration, issuedCertificates IssuedCertificates) (string, error) {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	result, err := tx.Exec("INSERT INTO configurations (name, value, checksum, rotation_phase, generator_type, parameters) VALUES(?,?,?,?,?,?)",
+		configuration.Name, configuration.Value, configuration.ParameterChecksum, configuration.RotationPhase, configuration.GeneratorType, configuration.Parameters)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+
+	for _, issuedCertificate := range issuedCertificates {
+		_, err = tx.Exec("INSERT INTO issued_certificates (ca_name, ca_configuration_id, serial_number, name, configuration_id) VALUES(?,?,?,?,?)",
+			issuedCertificate.CAName, issuedCertificate.CAConfigurationID, issuedCertificate.SerialNumber, issuedCertificate.Name, strconv.Itoa(int(id)))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(int(id)), nil
+}
+
+func (ms mysqlStore) PutRevocation(revocation Revocation) error {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("INSERT INTO revocations (ca_name, serial_number, reason, revoked_at) VALUES(?,?,?,?)",
+		revocation.CAName, revocation.SerialNumber, revocation.Reason, revocation.RevokedAt.Unix())
+
+	return err
+}
+
+func (ms mysqlStore) GetRevocations(caName string) (Revocations, error) {
+	var results Revocations
+
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query("SELECT ca_name, serial_number, reason, revoked_at FROM revocations WHERE ca_name = ? ORDER BY id", caName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var revocation Revocation
+		var revokedAt int64
+		if err := rows.Scan(&revocation.CAName, &revocation.SerialNumber, &revocation.Reason, &revokedAt); err != nil {
+			return results, err
+		}
+		revocation.RevokedAt = time.Unix(revokedAt, 0).UTC()
+		results = append(results, revocation)
+	}
+
+	return results, rows.Err()
+}
+
+func (ms mysqlStore) PutIssuedCertificate(issuedCertificate IssuedCertificate) error {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("INSERT INTO issued_certificates (ca_name, ca_configuration_id, serial_number, name, configuration_id) VALUES(?,?,?,?,?)",
+		issuedCertificate.CAName, issuedCertificate.CAConfigurationID, issuedCertificate.SerialNumber, issuedCertificate.Name, issuedCertificate.ConfigurationID)
+
+	return err
+}
+
+func (ms mysqlStore) GetIssuedCertificate(serialNumber string) (IssuedCertificate, error) {
+	result := IssuedCertificate{}
+
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return result, err
+	}
+
+	err = db.QueryRow("SELECT ca_name, ca_configuration_id, serial_number, name, configuration_id FROM issued_certificates WHERE serial_number = ?", serialNumber).Scan(&result.CAName, &result.CAConfigurationID, &result.SerialNumber, &result.Name, &result.ConfigurationID)
+	if err == sql.ErrNoRows {
+		return result, nil
+	}
+
+	return result, err
+}
+
+func (ms mysqlStore) GetIssuedCertificates(caName string) (IssuedCertificates, error) {
+	var results IssuedCertificates
+
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query("SELECT ca_name, ca_configuration_id, serial_number, name, configuration_id FROM issued_certificates WHERE ca_name = ? ORDER BY id", caName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var issuedCertificate IssuedCertificate
+		if err := rows.Scan(&issuedCertificate.CAName, &issuedCertificate.CAConfigurationID, &issuedCertificate.SerialNumber, &issuedCertificate.Name, &issuedCertificate.ConfigurationID); err != nil {
+			return results, err
+		}
+		results = append(results, issuedCertificate)
+	}
+
+	return results, rows.Err()
+}
+
+func (ms mysqlStore) PutRenewal(renewal Renewal) error {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("INSERT INTO renewals (name, previous_id, configuration_id, expires_at, renewed_at, dry_run) VALUES(?,?,?,?,?,?)",
+		renewal.Name, renewal.PreviousID, renewal.ConfigurationID, renewal.ExpiresAt.Unix(), renewal.RenewedAt.Unix(), renewal.DryRun)
+
+	return err
+}
+
+func (ms mysqlStore) GetRenewals(name string) (Renewals, error) {
+	var results Renewals
+
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query("SELECT name, previous_id, configuration_id, expires_at, renewed_at, dry_run FROM renewals WHERE name = ? ORDER BY id", name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var renewal Renewal
+		var expiresAt, renewedAt int64
+		if err := rows.Scan(&renewal.Name, &renewal.PreviousID, &renewal.ConfigurationID, &expiresAt, &renewedAt, &renewal.DryRun); err != nil {
+			return results, err
+		}
+		renewal.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+		renewal.RenewedAt = time.Unix(renewedAt, 0).UTC()
+		results = append(results, renewal)
+	}
+
+	return results, rows.Err()
+}
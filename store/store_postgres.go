@@ -3,6 +3,7 @@ package store
 import (
 	"database/sql"
 	"strconv"
+	"time"
 )
 
 type postgresStore struct {
@@ -37,7 +38,7 @@ func (ps postgresStore) GetByName(name string) (Configurations, error) {
 		return results, err
 	}
 
-	rows, err := db.Query("SELECT id, name, value, checksum FROM configurations WHERE name = $1 ORDER BY id DESC", name)
+	rows, err := db.Query("SELECT id, name, value, checksum, rotation_phase, generator_type, parameters FROM configurations WHERE name = $1 ORDER BY id DESC", name)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return results, nil
@@ -49,7 +50,7 @@ func (ps postgresStore) GetByName(name string) (Configurations, error) {
 
 	for rows.Next() {
 		var config Configuration
-		if err := rows.Scan(&config.ID, &config.Name, &config.Value, &config.ParameterChecksum); err != nil {
+		if err := rows.Scan(&config.ID, &config.Name, &config.Value, &config.ParameterChecksum, &config.RotationPhase, &config.GeneratorType, &config.Parameters); err != nil {
 			return results, err
 		}
 		results = append(results, config)
@@ -71,7 +72,7 @@ func (ps postgresStore) GetByID(id string) (Configuration, error) {
 		return result, err
 	}
 
-	err = db.QueryRow("SELECT id, name, value, checksum FROM configurations WHERE id = $1", id).Scan(&result.ID, &result.Name, &result.Value, &result.ParameterChecksum)
+	err = db.QueryRow("SELECT id, name, value, checksum, rotation_phase, generator_type, parameters FROM configurations WHERE id = $1", id).Scan(&result.ID, &result.Name, &result.Value, &result.ParameterChecksum, &result.RotationPhase, &result.GeneratorType, &result.Parameters)
 	if err == sql.ErrNoRows {
 		return result, nil
 	}
@@ -79,6 +80,35 @@ func (ps postgresStore) GetByID(id string) (Configuration, error) {
 	return result, err
 }
 
+func (ps postgresStore) GetLatestByNamePrefix(prefix string) (Configurations, error) {
+	var results Configurations
+
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query("SELECT id, name, value, checksum, rotation_phase, generator_type, parameters FROM configurations WHERE id IN (SELECT MAX(id) FROM configurations WHERE name LIKE $1 GROUP BY name) ORDER BY name", likePrefixPattern(prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var config Configuration
+		if err := rows.Scan(&config.ID, &config.Name, &config.Value, &config.ParameterChecksum, &config.RotationPhase, &config.GeneratorType, &config.Parameters); err != nil {
+			return results, err
+		}
+		results = append(results, config)
+	}
+
+	return results, rows.Err()
+}
+
 func (ps postgresStore) Delete(name string) (int, error) {
 
 	db, err := ps.dbProvider.Db()
@@ -98,3 +128,194 @@ func (ps postgresStore) Delete(name string) (int, error) {
 
 	return 0, err
 }
+
+func (ps postgresStore) SetRotationPhase(id string, phase string) error {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("UPDATE configurations SET rotation_phase = $1 WHERE id = $2", phase, id)
+	return err
+}
+
+// PutGenerated stores a generated configuration together with the records
+// of the certificates it holds, in a single transaction.
+func (ps postgresStore) PutGenerated(configuration Configuration, issuedCertificates IssuedCertificates) (string, error) {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var id int
+	err = tx.QueryRow("INSERT INTO configurations (name, value, checksum, rotation_phase, generator_type, parameters) VALUES($1, $2, $3, $4, $5, $6) RETURNING id",
+		configuration.Name, configuration.Value, configuration.ParameterChecksum, configuration.RotationPhase, configuration.GeneratorType, configuration.Parameters).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	for _, issuedCertificate := range issuedCertificates {
+		_, err = tx.Exec("INSERT INTO issued_certificates (ca_name, ca_configuration_id, serial_number, name, configuration_id) VALUES($1, $2, $3, $4, $5)",
+			issuedCertificate.CAName, issuedCertificate.CAConfigurationID, issuedCertificate.SerialNumber, issuedCertificate.Name, strconv.Itoa(id))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(id), nil
+}
+
+func (ps postgresStore) PutRevocation(revocation Revocation) error {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("INSERT INTO revocations (ca_name, serial_number, reason, revoked_at) VALUES($1, $2, $3, $4)",
+		revocation.CAName, revocation.SerialNumber, revocation.Reason, revocation.RevokedAt.Unix())
+
+	return err
+}
+
+func (ps postgresStore) GetRevocations(caName string) (Revocations, error) {
+	var results Revocations
+
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query("SELECT ca_name, serial_number, reason, revoked_at FROM revocations WHERE ca_name = $1 ORDER BY id", caName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var revocation Revocation
+		var revokedAt int64
+		if err := rows.Scan(&revocation.CAName, &revocation.SerialNumber, &revocation.Reason, &revokedAt); err != nil {
+			return results, err
+		}
+		revocation.RevokedAt = time.Unix(revokedAt, 0).UTC()
+		results = append(results, revocation)
+	}
+
+	return results, rows.Err()
+}
+
+func (ps postgresStore) PutIssuedCertificate(issuedCertificate IssuedCertificate) error {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("INSERT INTO issued_certificates (ca_name, ca_configuration_id, serial_number, name, configuration_id) VALUES($1, $2, $3, $4, $5)",
+		issuedCertificate.CAName, issuedCertificate.CAConfigurationID, issuedCertificate.SerialNumber, issuedCertificate.Name, issuedCertificate.ConfigurationID)
+
+	return err
+}
+
+func (ps postgresStore) GetIssuedCertificate(serialNumber string) (IssuedCertificate, error) {
+	result := IssuedCertificate{}
+
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return result, err
+	}
+
+	err = db.QueryRow("SELECT ca_name, ca_configuration_id, serial_number, name, configuration_id FROM issued_certificates WHERE serial_number = $1", serialNumber).Scan(&result.CAName, &result.CAConfigurationID, &result.SerialNumber, &result.Name, &result.ConfigurationID)
+	if err == sql.ErrNoRows {
+		return result, nil
+	}
+
+	return result, err
+}
+
+func (ps postgresStore) GetIssuedCertificates(caName string) (IssuedCertificates, error) {
+	var results IssuedCertificates
+
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query("SELECT ca_name, ca_configuration_id, serial_number, name, configuration_id FROM issued_certificates WHERE ca_name = $1 ORDER BY id", caName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var issuedCertificate IssuedCertificate
+		if err := rows.Scan(&issuedCertificate.CAName, &issuedCertificate.CAConfigurationID, &issuedCertificate.SerialNumber, &issuedCertificate.Name, &issuedCertificate.ConfigurationID); err != nil {
+			return results, err
+		}
+		results = append(results, issuedCertificate)
+	}
+
+	return results, rows.Err()
+}
+
+func (ps postgresStore) PutRenewal(renewal Renewal) error {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("INSERT INTO renewals (name, previous_id, configuration_id, expires_at, renewed_at, dry_run) VALUES($1, $2, $3, $4, $5, $6)",
+		renewal.Name, renewal.PreviousID, renewal.ConfigurationID, renewal.ExpiresAt.Unix(), renewal.RenewedAt.Unix(), renewal.DryRun)
+
+	return err
+}
+
+func (ps postgresStore) GetRenewals(name string) (Renewals, error) {
+	var results Renewals
+
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query("SELECT name, previous_id, configuration_id, expires_at, renewed_at, dry_run FROM renewals WHERE name = $1 ORDER BY id", name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var renewal Renewal
+		var expiresAt, renewedAt int64
+		if err := rows.Scan(&renewal.Name, &renewal.PreviousID, &renewal.ConfigurationID, &expiresAt, &renewedAt, &renewal.DryRun); err != nil {
+			return results, err
+		}
+		renewal.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+		renewal.RenewedAt = time.Unix(renewedAt, 0).UTC()
+		results = append(results, renewal)
+	}
+
+	return results, rows.Err()
+}
@@ -5,6 +5,7 @@ import (
 
 	"database/sql"
 	"errors"
+	"time"
 
 	fakes "github.com/shono09835/config-server/store/storefakes"
 
@@ -18,6 +19,7 @@ var _ = Describe("StorePostgres", func() {
 		fakeDbProvider *fakes.FakeDbProvider
 		fakeDb         *fakes.FakeIDb
 		fakeRow        *fakes.FakeIRow
+		fakeTx         *fakes.FakeITx
 		fakeRows       *fakes.FakeIRows
 		fakeResult     *fakes.FakeResult
 
@@ -28,6 +30,7 @@ var _ = Describe("StorePostgres", func() {
 		fakeDbProvider = &fakes.FakeDbProvider{}
 		fakeDb = &fakes.FakeIDb{}
 		fakeRow = &fakes.FakeIRow{}
+		fakeTx = &fakes.FakeITx{}
 		fakeRows = &fakes.FakeIRows{}
 		fakeResult = &fakes.FakeResult{}
 
@@ -43,7 +46,7 @@ var _ = Describe("StorePostgres", func() {
 			Expect(err).To(BeNil())
 			query, _ := fakeDb.QueryArgsForCall(0)
 
-			Expect(query).To(Equal("SELECT id, name, value, checksum FROM configurations WHERE name = $1 ORDER BY id DESC"))
+			Expect(query).To(Equal("SELECT id, name, value, checksum, rotation_phase, generator_type, parameters FROM configurations WHERE name = $1 ORDER BY id DESC"))
 		})
 
 		It("returns ALL values from db query", func() {
@@ -137,7 +140,7 @@ var _ = Describe("StorePostgres", func() {
 			Expect(err).To(BeNil())
 			query, _ := fakeDb.QueryRowArgsForCall(0)
 
-			Expect(query).To(Equal("SELECT id, name, value, checksum FROM configurations WHERE id = $1"))
+			Expect(query).To(Equal("SELECT id, name, value, checksum, rotation_phase, generator_type, parameters FROM configurations WHERE id = $1"))
 		})
 
 		It("returns value from db query", func() {
@@ -302,4 +305,364 @@ var _ = Describe("StorePostgres", func() {
 			})
 		})
 	})
+
+	Describe("GetLatestByNamePrefix", func() {
+		It("queries the database for the latest version of every name with the prefix", func() {
+			fakeDb.QueryReturns(fakeRows, nil)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetLatestByNamePrefix("/my_team/")
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.QueryArgsForCall(0)
+			Expect(query).To(Equal("SELECT id, name, value, checksum, rotation_phase, generator_type, parameters FROM configurations WHERE id IN (SELECT MAX(id) FROM configurations WHERE name LIKE $1 GROUP BY name) ORDER BY name"))
+			Expect(values).To(Equal([]interface{}{`/my\_team/%`}))
+		})
+
+		It("returns an error when the query fails", func() {
+			queryError := errors.New("query failure")
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.QueryReturns(nil, queryError)
+
+			_, err := store.GetLatestByNamePrefix("")
+			Expect(err).To(Equal(queryError))
+		})
+	})
+
+	Describe("SetRotationPhase", func() {
+		It("updates the rotation phase of the configuration", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			err := store.SetRotationPhase("1", "transitional")
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.ExecArgsForCall(0)
+			Expect(query).To(Equal("UPDATE configurations SET rotation_phase = $1 WHERE id = $2"))
+			Expect(values).To(Equal([]interface{}{"transitional", "1"}))
+		})
+
+		It("returns an error when the update fails", func() {
+			updateError := errors.New("update failure")
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.ExecReturns(nil, updateError)
+
+			err := store.SetRotationPhase("1", "transitional")
+			Expect(err).To(Equal(updateError))
+		})
+	})
+
+	Describe("PutGenerated", func() {
+		var (
+			configuration      Configuration
+			issuedCertificates IssuedCertificates
+		)
+
+		BeforeEach(func() {
+			configuration = Configuration{Name: "cert", Value: "value", ParameterChecksum: "checksum", GeneratorType: "certificate", Parameters: `{"ca":"my-ca"}`}
+			issuedCertificates = IssuedCertificates{{CAName: "my-ca", CAConfigurationID: "1", SerialNumber: "abc", Name: "cert"}}
+		})
+
+		It("inserts the configuration and the certificates it holds in a transaction", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.BeginReturns(fakeTx, nil)
+			fakeTx.QueryRowReturns(fakeRow)
+			fakeRow.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*int) = 9
+				return nil
+			}
+
+			id, err := store.PutGenerated(configuration, issuedCertificates)
+			Expect(err).To(BeNil())
+			Expect(id).To(Equal("9"))
+
+			Expect(fakeTx.QueryRowCallCount()).To(Equal(1))
+			query, values := fakeTx.QueryRowArgsForCall(0)
+			Expect(query).To(Equal("INSERT INTO configurations (name, value, checksum, rotation_phase, generator_type, parameters) VALUES($1, $2, $3, $4, $5, $6) RETURNING id"))
+			Expect(values).To(Equal([]interface{}{"cert", "value", "checksum", "", "certificate", `{"ca":"my-ca"}`}))
+
+			Expect(fakeTx.ExecCallCount()).To(Equal(1))
+			query, values = fakeTx.ExecArgsForCall(0)
+			Expect(query).To(Equal("INSERT INTO issued_certificates (ca_name, ca_configuration_id, serial_number, name, configuration_id) VALUES($1, $2, $3, $4, $5)"))
+			Expect(values).To(Equal([]interface{}{"my-ca", "1", "abc", "cert", "9"}))
+
+			Expect(fakeTx.CommitCallCount()).To(Equal(1))
+		})
+
+		It("rolls back when recording a certificate fails", func() {
+			insertError := errors.New("insert failure")
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.BeginReturns(fakeTx, nil)
+			fakeTx.QueryRowReturns(fakeRow)
+			fakeRow.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*int) = 9
+				return nil
+			}
+			fakeTx.ExecReturns(nil, insertError)
+
+			_, err := store.PutGenerated(configuration, issuedCertificates)
+			Expect(err).To(Equal(insertError))
+
+			Expect(fakeTx.CommitCallCount()).To(Equal(0))
+			Expect(fakeTx.RollbackCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when the transaction cannot be started", func() {
+			beginError := errors.New("begin failure")
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.BeginReturns(nil, beginError)
+
+			_, err := store.PutGenerated(configuration, issuedCertificates)
+			Expect(err).To(Equal(beginError))
+		})
+	})
+
+	Describe("PutRevocation", func() {
+		It("does an insert to the database", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			revokedAt := time.Unix(1500000000, 0)
+
+			err := store.PutRevocation(Revocation{CAName: "ca", SerialNumber: "abc", Reason: 1, RevokedAt: revokedAt})
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.ExecArgsForCall(0)
+			Expect(query).To(Equal("INSERT INTO revocations (ca_name, serial_number, reason, revoked_at) VALUES($1, $2, $3, $4)"))
+			Expect(values).To(Equal([]interface{}{"ca", "abc", 1, int64(1500000000)}))
+		})
+
+		It("returns an error when the insert fails", func() {
+			insertError := errors.New("insert failure")
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.ExecReturns(nil, insertError)
+
+			err := store.PutRevocation(Revocation{CAName: "ca", SerialNumber: "abc"})
+			Expect(err).To(Equal(insertError))
+		})
+	})
+
+	Describe("GetRevocations", func() {
+		It("queries the database for the revocations of a CA", func() {
+			index := -1
+			fakeRows.NextStub = func() bool {
+				index++
+				return index < 1
+			}
+
+			fakeRows.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*string) = "ca"
+				*dest[1].(*string) = "abc"
+				*dest[2].(*int) = 1
+				*dest[3].(*int64) = 1500000000
+				return nil
+			}
+
+			fakeDb.QueryReturns(fakeRows, nil)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			revocations, err := store.GetRevocations("ca")
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.QueryArgsForCall(0)
+			Expect(query).To(Equal("SELECT ca_name, serial_number, reason, revoked_at FROM revocations WHERE ca_name = $1 ORDER BY id"))
+			Expect(values[0]).To(Equal("ca"))
+
+			Expect(revocations).To(Equal(Revocations{
+				{CAName: "ca", SerialNumber: "abc", Reason: 1, RevokedAt: time.Unix(1500000000, 0).UTC()},
+			}))
+		})
+
+		It("returns an error when db query fails", func() {
+			queryError := errors.New("query failure")
+
+			fakeDb.QueryReturns(fakeRows, queryError)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetRevocations("ca")
+			Expect(err).To(Equal(queryError))
+		})
+
+		It("returns an error when iterating over the revocations fails", func() {
+			iterationError := errors.New("iteration failure")
+
+			fakeRows.NextReturns(false)
+			fakeRows.ErrReturns(iterationError)
+			fakeDb.QueryReturns(fakeRows, nil)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetRevocations("ca")
+			Expect(err).To(Equal(iterationError))
+		})
+	})
+
+	Describe("PutIssuedCertificate", func() {
+		It("does an insert to the database", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			err := store.PutIssuedCertificate(IssuedCertificate{CAName: "ca", CAConfigurationID: "2", SerialNumber: "abc", Name: "cert", ConfigurationID: "1"})
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.ExecArgsForCall(0)
+			Expect(query).To(Equal("INSERT INTO issued_certificates (ca_name, ca_configuration_id, serial_number, name, configuration_id) VALUES($1, $2, $3, $4, $5)"))
+			Expect(values).To(Equal([]interface{}{"ca", "2", "abc", "cert", "1"}))
+		})
+
+		It("returns an error when the insert fails", func() {
+			insertError := errors.New("insert failure")
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.ExecReturns(nil, insertError)
+
+			err := store.PutIssuedCertificate(IssuedCertificate{CAName: "ca", SerialNumber: "abc"})
+			Expect(err).To(Equal(insertError))
+		})
+	})
+
+	Describe("GetIssuedCertificate", func() {
+		It("queries the database for the certificate with the given serial number", func() {
+			fakeRow.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*string) = "ca"
+				*dest[1].(*string) = "2"
+				*dest[2].(*string) = "abc"
+				*dest[3].(*string) = "cert"
+				*dest[4].(*string) = "1"
+				return nil
+			}
+
+			fakeDb.QueryRowReturns(fakeRow)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			issuedCertificate, err := store.GetIssuedCertificate("abc")
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.QueryRowArgsForCall(0)
+			Expect(query).To(Equal("SELECT ca_name, ca_configuration_id, serial_number, name, configuration_id FROM issued_certificates WHERE serial_number = $1"))
+			Expect(values[0]).To(Equal("abc"))
+
+			Expect(issuedCertificate).To(Equal(IssuedCertificate{CAName: "ca", CAConfigurationID: "2", SerialNumber: "abc", Name: "cert", ConfigurationID: "1"}))
+		})
+
+		It("returns an empty result when no certificate is found", func() {
+			fakeRow.ScanReturns(sql.ErrNoRows)
+
+			fakeDb.QueryRowReturns(fakeRow)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			issuedCertificate, err := store.GetIssuedCertificate("abc")
+			Expect(err).To(BeNil())
+			Expect(issuedCertificate).To(Equal(IssuedCertificate{}))
+		})
+	})
+
+	Describe("GetIssuedCertificates", func() {
+		It("queries the database for the certificates issued by a CA", func() {
+			index := -1
+			fakeRows.NextStub = func() bool {
+				index++
+				return index < 1
+			}
+
+			fakeRows.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*string) = "ca"
+				*dest[1].(*string) = "2"
+				*dest[2].(*string) = "abc"
+				*dest[3].(*string) = "cert"
+				*dest[4].(*string) = "1"
+				return nil
+			}
+
+			fakeDb.QueryReturns(fakeRows, nil)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			issuedCertificates, err := store.GetIssuedCertificates("ca")
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.QueryArgsForCall(0)
+			Expect(query).To(Equal("SELECT ca_name, ca_configuration_id, serial_number, name, configuration_id FROM issued_certificates WHERE ca_name = $1 ORDER BY id"))
+			Expect(values[0]).To(Equal("ca"))
+
+			Expect(issuedCertificates).To(Equal(IssuedCertificates{
+				{CAName: "ca", CAConfigurationID: "2", SerialNumber: "abc", Name: "cert", ConfigurationID: "1"},
+			}))
+		})
+
+		It("returns an error when db query fails", func() {
+			queryError := errors.New("query failure")
+
+			fakeDb.QueryReturns(fakeRows, queryError)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetIssuedCertificates("ca")
+			Expect(err).To(Equal(queryError))
+		})
+	})
+
+	Describe("PutRenewal", func() {
+		It("does an insert to the database", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			err := store.PutRenewal(Renewal{
+				Name:            "cert",
+				PreviousID:      "1",
+				ConfigurationID: "2",
+				ExpiresAt:       time.Unix(1500000000, 0),
+				RenewedAt:       time.Unix(1400000000, 0),
+			})
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.ExecArgsForCall(0)
+			Expect(query).To(Equal("INSERT INTO renewals (name, previous_id, configuration_id, expires_at, renewed_at, dry_run) VALUES($1, $2, $3, $4, $5, $6)"))
+			Expect(values).To(Equal([]interface{}{"cert", "1", "2", int64(1500000000), int64(1400000000), false}))
+		})
+
+		It("returns an error when the insert fails", func() {
+			insertError := errors.New("insert failure")
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.ExecReturns(nil, insertError)
+
+			err := store.PutRenewal(Renewal{Name: "cert"})
+			Expect(err).To(Equal(insertError))
+		})
+	})
+
+	Describe("GetRenewals", func() {
+		It("queries the database for the renewals of a name", func() {
+			index := -1
+			fakeRows.NextStub = func() bool {
+				index++
+				return index < 1
+			}
+
+			fakeRows.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*string) = "cert"
+				*dest[1].(*string) = "1"
+				*dest[2].(*string) = ""
+				*dest[3].(*int64) = 1500000000
+				*dest[4].(*int64) = 1400000000
+				*dest[5].(*bool) = true
+				return nil
+			}
+
+			fakeDb.QueryReturns(fakeRows, nil)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			renewals, err := store.GetRenewals("cert")
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.QueryArgsForCall(0)
+			Expect(query).To(Equal("SELECT name, previous_id, configuration_id, expires_at, renewed_at, dry_run FROM renewals WHERE name = $1 ORDER BY id"))
+			Expect(values[0]).To(Equal("cert"))
+
+			Expect(renewals).To(Equal(Renewals{
+				{Name: "cert", PreviousID: "1", ExpiresAt: time.Unix(1500000000, 0).UTC(), RenewedAt: time.Unix(1400000000, 0).UTC(), DryRun: true},
+			}))
+		})
+
+		It("returns an error when db query fails", func() {
+			queryError := errors.New("query failure")
+
+			fakeDb.QueryReturns(fakeRows, queryError)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetRenewals("cert")
+			Expect(err).To(Equal(queryError))
+		})
+	})
 })
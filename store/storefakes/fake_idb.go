@@ -51,6 +51,13 @@ type FakeIDb struct {
 	CloseStub        func()
 	closeMutex       sync.RWMutex
 	closeArgsForCall []struct{}
+	BeginStub        func() (store.ITx, error)
+	beginMutex       sync.RWMutex
+	beginArgsForCall []struct{}
+	beginReturns     struct {
+		result1 store.ITx
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -220,6 +227,31 @@ func (fake *FakeIDb) CloseCallCount() int {
 	return len(fake.closeArgsForCall)
 }
 
+func (fake *FakeIDb) Begin() (store.ITx, error) {
+	fake.beginMutex.Lock()
+	fake.beginArgsForCall = append(fake.beginArgsForCall, struct{}{})
+	fake.recordInvocation("Begin", []interface{}{})
+	fake.beginMutex.Unlock()
+	if fake.BeginStub != nil {
+		return fake.BeginStub()
+	}
+	return fake.beginReturns.result1, fake.beginReturns.result2
+}
+
+func (fake *FakeIDb) BeginCallCount() int {
+	fake.beginMutex.RLock()
+	defer fake.beginMutex.RUnlock()
+	return len(fake.beginArgsForCall)
+}
+
+func (fake *FakeIDb) BeginReturns(result1 store.ITx, result2 error) {
+	fake.BeginStub = nil
+	fake.beginReturns = struct {
+		result1 store.ITx
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIDb) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -235,6 +267,8 @@ func (fake *FakeIDb) Invocations() map[string][][]interface{} {
 	defer fake.setMaxIdleConnsMutex.RUnlock()
 	fake.closeMutex.RLock()
 	defer fake.closeMutex.RUnlock()
+	fake.beginMutex.RLock()
+	defer fake.beginMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -26,6 +26,15 @@ type FakeIRows struct {
 	closeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ErrStub        func() error
+	errMutex       sync.RWMutex
+	errArgsForCall []struct{}
+	errReturns     struct {
+		result1 error
+	}
+	errReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ScanStub        func(dest ...interface{}) error
 	scanMutex       sync.RWMutex
 	scanArgsForCall []struct {
@@ -121,6 +130,46 @@ func (fake *FakeIRows) CloseReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIRows) Err() error {
+	fake.errMutex.Lock()
+	ret, specificReturn := fake.errReturnsOnCall[len(fake.errArgsForCall)]
+	fake.errArgsForCall = append(fake.errArgsForCall, struct{}{})
+	fake.recordInvocation("Err", []interface{}{})
+	fake.errMutex.Unlock()
+	if fake.ErrStub != nil {
+		return fake.ErrStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.errReturns.result1
+}
+
+func (fake *FakeIRows) ErrCallCount() int {
+	fake.errMutex.RLock()
+	defer fake.errMutex.RUnlock()
+	return len(fake.errArgsForCall)
+}
+
+func (fake *FakeIRows) ErrReturns(result1 error) {
+	fake.ErrStub = nil
+	fake.errReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIRows) ErrReturnsOnCall(i int, result1 error) {
+	fake.ErrStub = nil
+	if fake.errReturnsOnCall == nil {
+		fake.errReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.errReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIRows) Scan(dest ...interface{}) error {
 	fake.scanMutex.Lock()
 	ret, specificReturn := fake.scanReturnsOnCall[len(fake.scanArgsForCall)]
@@ -176,6 +225,8 @@ func (fake *FakeIRows) Invocations() map[string][][]interface{} {
 	defer fake.nextMutex.RUnlock()
 	fake.closeMutex.RLock()
 	defer fake.closeMutex.RUnlock()
+	fake.errMutex.RLock()
+	defer fake.errMutex.RUnlock()
 	fake.scanMutex.RLock()
 	defer fake.scanMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
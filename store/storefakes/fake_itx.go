@@ -0,0 +1,188 @@
+// This file was generated by counterfeiter
+package storefakes
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/shono09835/config-server/store"
+)
+
+type FakeITx struct {
+	ExecStub        func(query string, args ...interface{}) (sql.Result, error)
+	execMutex       sync.RWMutex
+	execArgsForCall []struct {
+		query string
+		args  []interface{}
+	}
+	execReturns struct {
+		result1 sql.Result
+		result2 error
+	}
+	QueryRowStub        func(query string, args ...interface{}) store.IRow
+	queryRowMutex       sync.RWMutex
+	queryRowArgsForCall []struct {
+		query string
+		args  []interface{}
+	}
+	queryRowReturns struct {
+		result1 store.IRow
+	}
+	CommitStub        func() error
+	commitMutex       sync.RWMutex
+	commitArgsForCall []struct{}
+	commitReturns     struct {
+		result1 error
+	}
+	RollbackStub        func() error
+	rollbackMutex       sync.RWMutex
+	rollbackArgsForCall []struct{}
+	rollbackReturns     struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeITx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	fake.execMutex.Lock()
+	fake.execArgsForCall = append(fake.execArgsForCall, struct {
+		query string
+		args  []interface{}
+	}{query, args})
+	fake.recordInvocation("Exec", []interface{}{query, args})
+	fake.execMutex.Unlock()
+	if fake.ExecStub != nil {
+		return fake.ExecStub(query, args...)
+	}
+	return fake.execReturns.result1, fake.execReturns.result2
+}
+
+func (fake *FakeITx) ExecCallCount() int {
+	fake.execMutex.RLock()
+	defer fake.execMutex.RUnlock()
+	return len(fake.execArgsForCall)
+}
+
+func (fake *FakeITx) ExecArgsForCall(i int) (string, []interface{}) {
+	fake.execMutex.RLock()
+	defer fake.execMutex.RUnlock()
+	return fake.execArgsForCall[i].query, fake.execArgsForCall[i].args
+}
+
+func (fake *FakeITx) ExecReturns(result1 sql.Result, result2 error) {
+	fake.ExecStub = nil
+	fake.execReturns = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeITx) QueryRow(query string, args ...interface{}) store.IRow {
+	fake.queryRowMutex.Lock()
+	fake.queryRowArgsForCall = append(fake.queryRowArgsForCall, struct {
+		query string
+		args  []interface{}
+	}{query, args})
+	fake.recordInvocation("QueryRow", []interface{}{query, args})
+	fake.queryRowMutex.Unlock()
+	if fake.QueryRowStub != nil {
+		return fake.QueryRowStub(query, args...)
+	}
+	return fake.queryRowReturns.result1
+}
+
+func (fake *FakeITx) QueryRowCallCount() int {
+	fake.queryRowMutex.RLock()
+	defer fake.queryRowMutex.RUnlock()
+	return len(fake.queryRowArgsForCall)
+}
+
+func (fake *FakeITx) QueryRowArgsForCall(i int) (string, []interface{}) {
+	fake.queryRowMutex.RLock()
+	defer fake.queryRowMutex.RUnlock()
+	return fake.queryRowArgsForCall[i].query, fake.queryRowArgsForCall[i].args
+}
+
+func (fake *FakeITx) QueryRowReturns(result1 store.IRow) {
+	fake.QueryRowStub = nil
+	fake.queryRowReturns = struct {
+		result1 store.IRow
+	}{result1}
+}
+
+func (fake *FakeITx) Commit() error {
+	fake.commitMutex.Lock()
+	fake.commitArgsForCall = append(fake.commitArgsForCall, struct{}{})
+	fake.recordInvocation("Commit", []interface{}{})
+	fake.commitMutex.Unlock()
+	if fake.CommitStub != nil {
+		return fake.CommitStub()
+	}
+	return fake.commitReturns.result1
+}
+
+func (fake *FakeITx) CommitCallCount() int {
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	return len(fake.commitArgsForCall)
+}
+
+func (fake *FakeITx) CommitReturns(result1 error) {
+	fake.CommitStub = nil
+	fake.commitReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeITx) Rollback() error {
+	fake.rollbackMutex.Lock()
+	fake.rollbackArgsForCall = append(fake.rollbackArgsForCall, struct{}{})
+	fake.recordInvocation("Rollback", []interface{}{})
+	fake.rollbackMutex.Unlock()
+	if fake.RollbackStub != nil {
+		return fake.RollbackStub()
+	}
+	return fake.rollbackReturns.result1
+}
+
+func (fake *FakeITx) RollbackCallCount() int {
+	fake.rollbackMutex.RLock()
+	defer fake.rollbackMutex.RUnlock()
+	return len(fake.rollbackArgsForCall)
+}
+
+func (fake *FakeITx) RollbackReturns(result1 error) {
+	fake.RollbackStub = nil
+	fake.rollbackReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeITx) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.execMutex.RLock()
+	defer fake.execMutex.RUnlock()
+	fake.queryRowMutex.RLock()
+	defer fake.queryRowMutex.RUnlock()
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	fake.rollbackMutex.RLock()
+	defer fake.rollbackMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeITx) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ store.ITx = new(FakeITx)
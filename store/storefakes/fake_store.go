@@ -46,6 +46,94 @@ type FakeStore struct {
 		result1 int
 		result2 error
 	}
+	PutRevocationStub        func(store.Revocation) error
+	putRevocationMutex       sync.RWMutex
+	putRevocationArgsForCall []struct {
+		revocation store.Revocation
+	}
+	putRevocationReturns struct {
+		result1 error
+	}
+	GetRevocationsStub        func(string) (store.Revocations, error)
+	getRevocationsMutex       sync.RWMutex
+	getRevocationsArgsForCall []struct {
+		caName string
+	}
+	getRevocationsReturns struct {
+		result1 store.Revocations
+		result2 error
+	}
+	PutIssuedCertificateStub        func(store.IssuedCertificate) error
+	putIssuedCertificateMutex       sync.RWMutex
+	putIssuedCertificateArgsForCall []struct {
+		issuedCertificate store.IssuedCertificate
+	}
+	putIssuedCertificateReturns struct {
+		result1 error
+	}
+	GetIssuedCertificateStub        func(string) (store.IssuedCertificate, error)
+	getIssuedCertificateMutex       sync.RWMutex
+	getIssuedCertificateArgsForCall []struct {
+		serialNumber string
+	}
+	getIssuedCertificateReturns struct {
+		result1 store.IssuedCertificate
+		result2 error
+	}
+	SetRotationPhaseStub        func(string, string) error
+	setRotationPhaseMutex       sync.RWMutex
+	setRotationPhaseArgsForCall []struct {
+		id    string
+		phase string
+	}
+	setRotationPhaseReturns struct {
+		result1 error
+	}
+	GetLatestByNamePrefixStub        func(string) (store.Configurations, error)
+	getLatestByNamePrefixMutex       sync.RWMutex
+	getLatestByNamePrefixArgsForCall []struct {
+		prefix string
+	}
+	getLatestByNamePrefixReturns struct {
+		result1 store.Configurations
+		result2 error
+	}
+	PutRenewalStub        func(store.Renewal) error
+	putRenewalMutex       sync.RWMutex
+	putRenewalArgsForCall []struct {
+		renewal store.Renewal
+	}
+	putRenewalReturns struct {
+		result1 error
+	}
+	GetRenewalsStub        func(string) (store.Renewals, error)
+	getRenewalsMutex       sync.RWMutex
+	getRenewalsArgsForCall []struct {
+		name string
+	}
+	getRenewalsReturns struct {
+		result1 store.Renewals
+		result2 error
+	}
+	GetIssuedCertificatesStub        func(string) (store.IssuedCertificates, error)
+	getIssuedCertificatesMutex       sync.RWMutex
+	getIssuedCertificatesArgsForCall []struct {
+		caName string
+	}
+	getIssuedCertificatesReturns struct {
+		result1 store.IssuedCertificates
+		result2 error
+	}
+	PutGeneratedStub        func(store.Configuration, store.IssuedCertificates) (string, error)
+	putGeneratedMutex       sync.RWMutex
+	putGeneratedArgsForCall []struct {
+		configuration      store.Configuration
+		issuedCertificates store.IssuedCertificates
+	}
+	putGeneratedReturns struct {
+		result1 string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -184,6 +272,334 @@ func (fake *FakeStore) DeleteReturns(result1 int, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeStore) PutRevocation(revocation store.Revocation) error {
+	fake.putRevocationMutex.Lock()
+	fake.putRevocationArgsForCall = append(fake.putRevocationArgsForCall, struct {
+		revocation store.Revocation
+	}{revocation})
+	fake.recordInvocation("PutRevocation", []interface{}{revocation})
+	fake.putRevocationMutex.Unlock()
+	if fake.PutRevocationStub != nil {
+		return fake.PutRevocationStub(revocation)
+	}
+	return fake.putRevocationReturns.result1
+}
+
+func (fake *FakeStore) PutRevocationCallCount() int {
+	fake.putRevocationMutex.RLock()
+	defer fake.putRevocationMutex.RUnlock()
+	return len(fake.putRevocationArgsForCall)
+}
+
+func (fake *FakeStore) PutRevocationArgsForCall(i int) store.Revocation {
+	fake.putRevocationMutex.RLock()
+	defer fake.putRevocationMutex.RUnlock()
+	return fake.putRevocationArgsForCall[i].revocation
+}
+
+func (fake *FakeStore) PutRevocationReturns(result1 error) {
+	fake.PutRevocationStub = nil
+	fake.putRevocationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStore) GetRevocations(caName string) (store.Revocations, error) {
+	fake.getRevocationsMutex.Lock()
+	fake.getRevocationsArgsForCall = append(fake.getRevocationsArgsForCall, struct {
+		caName string
+	}{caName})
+	fake.recordInvocation("GetRevocations", []interface{}{caName})
+	fake.getRevocationsMutex.Unlock()
+	if fake.GetRevocationsStub != nil {
+		return fake.GetRevocationsStub(caName)
+	}
+	return fake.getRevocationsReturns.result1, fake.getRevocationsReturns.result2
+}
+
+func (fake *FakeStore) GetRevocationsCallCount() int {
+	fake.getRevocationsMutex.RLock()
+	defer fake.getRevocationsMutex.RUnlock()
+	return len(fake.getRevocationsArgsForCall)
+}
+
+func (fake *FakeStore) GetRevocationsArgsForCall(i int) string {
+	fake.getRevocationsMutex.RLock()
+	defer fake.getRevocationsMutex.RUnlock()
+	return fake.getRevocationsArgsForCall[i].caName
+}
+
+func (fake *FakeStore) GetRevocationsReturns(result1 store.Revocations, result2 error) {
+	fake.GetRevocationsStub = nil
+	fake.getRevocationsReturns = struct {
+		result1 store.Revocations
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) PutIssuedCertificate(issuedCertificate store.IssuedCertificate) error {
+	fake.putIssuedCertificateMutex.Lock()
+	fake.putIssuedCertificateArgsForCall = append(fake.putIssuedCertificateArgsForCall, struct {
+		issuedCertificate store.IssuedCertificate
+	}{issuedCertificate})
+	fake.recordInvocation("PutIssuedCertificate", []interface{}{issuedCertificate})
+	fake.putIssuedCertificateMutex.Unlock()
+	if fake.PutIssuedCertificateStub != nil {
+		return fake.PutIssuedCertificateStub(issuedCertificate)
+	}
+	return fake.putIssuedCertificateReturns.result1
+}
+
+func (fake *FakeStore) PutIssuedCertificateCallCount() int {
+	fake.putIssuedCertificateMutex.RLock()
+	defer fake.putIssuedCertificateMutex.RUnlock()
+	return len(fake.putIssuedCertificateArgsForCall)
+}
+
+func (fake *FakeStore) PutIssuedCertificateArgsForCall(i int) store.IssuedCertificate {
+	fake.putIssuedCertificateMutex.RLock()
+	defer fake.putIssuedCertificateMutex.RUnlock()
+	return fake.putIssuedCertificateArgsForCall[i].issuedCertificate
+}
+
+func (fake *FakeStore) PutIssuedCertificateReturns(result1 error) {
+	fake.PutIssuedCertificateStub = nil
+	fake.putIssuedCertificateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStore) GetIssuedCertificate(serialNumber string) (store.IssuedCertificate, error) {
+	fake.getIssuedCertificateMutex.Lock()
+	fake.getIssuedCertificateArgsForCall = append(fake.getIssuedCertificateArgsForCall, struct {
+		serialNumber string
+	}{serialNumber})
+	fake.recordInvocation("GetIssuedCertificate", []interface{}{serialNumber})
+	fake.getIssuedCertificateMutex.Unlock()
+	if fake.GetIssuedCertificateStub != nil {
+		return fake.GetIssuedCertificateStub(serialNumber)
+	}
+	return fake.getIssuedCertificateReturns.result1, fake.getIssuedCertificateReturns.result2
+}
+
+func (fake *FakeStore) GetIssuedCertificateCallCount() int {
+	fake.getIssuedCertificateMutex.RLock()
+	defer fake.getIssuedCertificateMutex.RUnlock()
+	return len(fake.getIssuedCertificateArgsForCall)
+}
+
+func (fake *FakeStore) GetIssuedCertificateArgsForCall(i int) string {
+	fake.getIssuedCertificateMutex.RLock()
+	defer fake.getIssuedCertificateMutex.RUnlock()
+	return fake.getIssuedCertificateArgsForCall[i].serialNumber
+}
+
+func (fake *FakeStore) GetIssuedCertificateReturns(result1 store.IssuedCertificate, result2 error) {
+	fake.GetIssuedCertificateStub = nil
+	fake.getIssuedCertificateReturns = struct {
+		result1 store.IssuedCertificate
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) SetRotationPhase(id string, phase string) error {
+	fake.setRotationPhaseMutex.Lock()
+	fake.setRotationPhaseArgsForCall = append(fake.setRotationPhaseArgsForCall, struct {
+		id    string
+		phase string
+	}{id, phase})
+	fake.recordInvocation("SetRotationPhase", []interface{}{id, phase})
+	fake.setRotationPhaseMutex.Unlock()
+	if fake.SetRotationPhaseStub != nil {
+		return fake.SetRotationPhaseStub(id, phase)
+	}
+	return fake.setRotationPhaseReturns.result1
+}
+
+func (fake *FakeStore) SetRotationPhaseCallCount() int {
+	fake.setRotationPhaseMutex.RLock()
+	defer fake.setRotationPhaseMutex.RUnlock()
+	return len(fake.setRotationPhaseArgsForCall)
+}
+
+func (fake *FakeStore) SetRotationPhaseArgsForCall(i int) (string, string) {
+	fake.setRotationPhaseMutex.RLock()
+	defer fake.setRotationPhaseMutex.RUnlock()
+	return fake.setRotationPhaseArgsForCall[i].id, fake.setRotationPhaseArgsForCall[i].phase
+}
+
+func (fake *FakeStore) SetRotationPhaseReturns(result1 error) {
+	fake.SetRotationPhaseStub = nil
+	fake.setRotationPhaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStore) GetLatestByNamePrefix(prefix string) (store.Configurations, error) {
+	fake.getLatestByNamePrefixMutex.Lock()
+	fake.getLatestByNamePrefixArgsForCall = append(fake.getLatestByNamePrefixArgsForCall, struct {
+		prefix string
+	}{prefix})
+	fake.recordInvocation("GetLatestByNamePrefix", []interface{}{prefix})
+	fake.getLatestByNamePrefixMutex.Unlock()
+	if fake.GetLatestByNamePrefixStub != nil {
+		return fake.GetLatestByNamePrefixStub(prefix)
+	}
+	return fake.getLatestByNamePrefixReturns.result1, fake.getLatestByNamePrefixReturns.result2
+}
+
+func (fake *FakeStore) GetLatestByNamePrefixCallCount() int {
+	fake.getLatestByNamePrefixMutex.RLock()
+	defer fake.getLatestByNamePrefixMutex.RUnlock()
+	return len(fake.getLatestByNamePrefixArgsForCall)
+}
+
+func (fake *FakeStore) GetLatestByNamePrefixArgsForCall(i int) string {
+	fake.getLatestByNamePrefixMutex.RLock()
+	defer fake.getLatestByNamePrefixMutex.RUnlock()
+	return fake.getLatestByNamePrefixArgsForCall[i].prefix
+}
+
+func (fake *FakeStore) GetLatestByNamePrefixReturns(result1 store.Configurations, result2 error) {
+	fake.GetLatestByNamePrefixStub = nil
+	fake.getLatestByNamePrefixReturns = struct {
+		result1 store.Configurations
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) PutRenewal(renewal store.Renewal) error {
+	fake.putRenewalMutex.Lock()
+	fake.putRenewalArgsForCall = append(fake.putRenewalArgsForCall, struct {
+		renewal store.Renewal
+	}{renewal})
+	fake.recordInvocation("PutRenewal", []interface{}{renewal})
+	fake.putRenewalMutex.Unlock()
+	if fake.PutRenewalStub != nil {
+		return fake.PutRenewalStub(renewal)
+	}
+	return fake.putRenewalReturns.result1
+}
+
+func (fake *FakeStore) PutRenewalCallCount() int {
+	fake.putRenewalMutex.RLock()
+	defer fake.putRenewalMutex.RUnlock()
+	return len(fake.putRenewalArgsForCall)
+}
+
+func (fake *FakeStore) PutRenewalArgsForCall(i int) store.Renewal {
+	fake.putRenewalMutex.RLock()
+	defer fake.putRenewalMutex.RUnlock()
+	return fake.putRenewalArgsForCall[i].renewal
+}
+
+func (fake *FakeStore) PutRenewalReturns(result1 error) {
+	fake.PutRenewalStub = nil
+	fake.putRenewalReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStore) GetRenewals(name string) (store.Renewals, error) {
+	fake.getRenewalsMutex.Lock()
+	fake.getRenewalsArgsForCall = append(fake.getRenewalsArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("GetRenewals", []interface{}{name})
+	fake.getRenewalsMutex.Unlock()
+	if fake.GetRenewalsStub != nil {
+		return fake.GetRenewalsStub(name)
+	}
+	return fake.getRenewalsReturns.result1, fake.getRenewalsReturns.result2
+}
+
+func (fake *FakeStore) GetRenewalsCallCount() int {
+	fake.getRenewalsMutex.RLock()
+	defer fake.getRenewalsMutex.RUnlock()
+	return len(fake.getRenewalsArgsForCall)
+}
+
+func (fake *FakeStore) GetRenewalsArgsForCall(i int) string {
+	fake.getRenewalsMutex.RLock()
+	defer fake.getRenewalsMutex.RUnlock()
+	return fake.getRenewalsArgsForCall[i].name
+}
+
+func (fake *FakeStore) GetRenewalsReturns(result1 store.Renewals, result2 error) {
+	fake.GetRenewalsStub = nil
+	fake.getRenewalsReturns = struct {
+		result1 store.Renewals
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) GetIssuedCertificates(caName string) (store.IssuedCertificates, error) {
+	fake.getIssuedCertificatesMutex.Lock()
+	fake.getIssuedCertificatesArgsForCall = append(fake.getIssuedCertificatesArgsForCall, struct {
+		caName string
+	}{caName})
+	fake.recordInvocation("GetIssuedCertificates", []interface{}{caName})
+	fake.getIssuedCertificatesMutex.Unlock()
+	if fake.GetIssuedCertificatesStub != nil {
+		return fake.GetIssuedCertificatesStub(caName)
+	}
+	return fake.getIssuedCertificatesReturns.result1, fake.getIssuedCertificatesReturns.result2
+}
+
+func (fake *FakeStore) GetIssuedCertificatesCallCount() int {
+	fake.getIssuedCertificatesMutex.RLock()
+	defer fake.getIssuedCertificatesMutex.RUnlock()
+	return len(fake.getIssuedCertificatesArgsForCall)
+}
+
+func (fake *FakeStore) GetIssuedCertificatesArgsForCall(i int) string {
+	fake.getIssuedCertificatesMutex.RLock()
+	defer fake.getIssuedCertificatesMutex.RUnlock()
+	return fake.getIssuedCertificatesArgsForCall[i].caName
+}
+
+func (fake *FakeStore) GetIssuedCertificatesReturns(result1 store.IssuedCertificates, result2 error) {
+	fake.GetIssuedCertificatesStub = nil
+	fake.getIssuedCertificatesReturns = struct {
+		result1 store.IssuedCertificates
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) PutGenerated(configuration store.Configuration, issuedCertificates store.IssuedCertificates) (string, error) {
+	fake.putGeneratedMutex.Lock()
+	fake.putGeneratedArgsForCall = append(fake.putGeneratedArgsForCall, struct {
+		configuration      store.Configuration
+		issuedCertificates store.IssuedCertificates
+	}{configuration, issuedCertificates})
+	fake.recordInvocation("PutGenerated", []interface{}{configuration, issuedCertificates})
+	fake.putGeneratedMutex.Unlock()
+	if fake.PutGeneratedStub != nil {
+		return fake.PutGeneratedStub(configuration, issuedCertificates)
+	}
+	return fake.putGeneratedReturns.result1, fake.putGeneratedReturns.result2
+}
+
+func (fake *FakeStore) PutGeneratedCallCount() int {
+	fake.putGeneratedMutex.RLock()
+	defer fake.putGeneratedMutex.RUnlock()
+	return len(fake.putGeneratedArgsForCall)
+}
+
+func (fake *FakeStore) PutGeneratedArgsForCall(i int) (store.Configuration, store.IssuedCertificates) {
+	fake.putGeneratedMutex.RLock()
+	defer fake.putGeneratedMutex.RUnlock()
+	return fake.putGeneratedArgsForCall[i].configuration, fake.putGeneratedArgsForCall[i].issuedCertificates
+}
+
+func (fake *FakeStore) PutGeneratedReturns(result1 string, result2 error) {
+	fake.PutGeneratedStub = nil
+	fake.putGeneratedReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStore) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -195,6 +611,26 @@ func (fake *FakeStore) Invocations() map[string][][]interface{} {
 	defer fake.getByIDMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
+	fake.putRevocationMutex.RLock()
+	defer fake.putRevocationMutex.RUnlock()
+	fake.getRevocationsMutex.RLock()
+	defer fake.getRevocationsMutex.RUnlock()
+	fake.putIssuedCertificateMutex.RLock()
+	defer fake.putIssuedCertificateMutex.RUnlock()
+	fake.getIssuedCertificateMutex.RLock()
+	defer fake.getIssuedCertificateMutex.RUnlock()
+	fake.setRotationPhaseMutex.RLock()
+	defer fake.setRotationPhaseMutex.RUnlock()
+	fake.getLatestByNamePrefixMutex.RLock()
+	defer fake.getLatestByNamePrefixMutex.RUnlock()
+	fake.putRenewalMutex.RLock()
+	defer fake.putRenewalMutex.RUnlock()
+	fake.getRenewalsMutex.RLock()
+	defer fake.getRenewalsMutex.RUnlock()
+	fake.getIssuedCertificatesMutex.RLock()
+	defer fake.getIssuedCertificatesMutex.RUnlock()
+	fake.putGeneratedMutex.RLock()
+	defer fake.putGeneratedMutex.RUnlock()
 	return fake.invocations
 }
 
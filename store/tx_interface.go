@@ -0,0 +1,12 @@
+package store
+
+import "database/sql"
+
+//go:generate counterfeiter . ITx
+
+type ITx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) IRow
+	Commit() error
+	Rollback() error
+}
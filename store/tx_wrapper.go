@@ -0,0 +1,29 @@
+package store
+
+import (
+	"database/sql"
+)
+
+type TxWrapper struct {
+	tx *sql.Tx
+}
+
+func NewTxWrapper(tx *sql.Tx) TxWrapper {
+	return TxWrapper{tx}
+}
+
+func (w TxWrapper) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return w.tx.Exec(query, args...)
+}
+
+func (w TxWrapper) QueryRow(query string, args ...interface{}) IRow {
+	return NewRowWrapper(w.tx.QueryRow(query, args...))
+}
+
+func (w TxWrapper) Commit() error {
+	return w.tx.Commit()
+}
+
+func (w TxWrapper) Rollback() error {
+	return w.tx.Rollback()
+}
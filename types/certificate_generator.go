@@ -5,51 +5,108 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
 	"encoding/pem"
 	"math/big"
 	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
 	"crypto/sha1"
 
 	"github.com/cloudfoundry/bosh-utils/errors"
+
+	"github.com/shono09835/config-server/config"
 )
 
+var spiffeTrustDomain = regexp.MustCompile(`^[a-z0-9._-]+$`)
+
 type CertificateGenerator struct {
 	loader CertsLoader
+	config config.GeneratorsConfig
 }
 
 type CertResponse struct {
 	Certificate string `json:"certificate" yaml:"certificate"`
 	PrivateKey  string `json:"private_key" yaml:"private_key"`
 	CA          string `json:"ca"          yaml:"ca"`
+	Chain       string `json:"chain"       yaml:"chain"`
 }
 
 type certParams struct {
-	CommonName       string   `yaml:"common_name"`
-	Organization     string   `yaml:"organization"`
-	Organizations    []string `yaml:"organizations"`
-	AlternativeNames []string `yaml:"alternative_names"`
-	IsCA             bool     `yaml:"is_ca"`
-	CAName           string   `yaml:"ca"`
-	ExtKeyUsage      []string `yaml:"extended_key_usage"`
-	Duration         int64    `yaml:"duration"`
+	CommonName          string   `yaml:"common_name"`
+	Country             string   `yaml:"country"`
+	State               string   `yaml:"state"`
+	Locality            string   `yaml:"locality"`
+	Organization        string   `yaml:"organization"`
+	Organizations       []string `yaml:"organizations"`
+	OrganizationalUnits []string `yaml:"organizational_units"`
+	AlternativeNames    []string `yaml:"alternative_names"`
+	URISANs             []string `yaml:"uri_sans"`
+	EmailSANs           []string `yaml:"email_sans"`
+	IsCA                bool     `yaml:"is_ca"`
+	CAName              string   `yaml:"ca"`
+	KeyUsage            []string `yaml:"key_usage"`
+	ExtKeyUsage         []string `yaml:"extended_key_usage"`
+	Duration            int64    `yaml:"duration"`
+	MaxPathLen          *int     `yaml:"max_path_len"`
+	PermittedDNSDomains []string `yaml:"permitted_dns_domains"`
+	ExcludedDNSDomains  []string `yaml:"excluded_dns_domains"`
+	PermittedIPRanges   []string `yaml:"permitted_ip_ranges"`
+	ExcludedIPRanges    []string `yaml:"excluded_ip_ranges"`
+	ReuseKey            bool     `yaml:"reuse_key"`
 }
 
 var supportedCertParameters = []string{
 	"common_name",
+	"country",
+	"state",
+	"locality",
 	"organization",
+	"organizations",
+	"organizational_units",
 	"alternative_names",
+	"uri_sans",
+	"email_sans",
 	"is_ca",
 	"ca",
+	"key_usage",
 	"extended_key_usage",
 	"duration",
+	"max_path_len",
+	"permitted_dns_domains",
+	"excluded_dns_domains",
+	"permitted_ip_ranges",
+	"excluded_ip_ranges",
+	"reuse_key",
+}
+
+var keyUsages = map[string]x509.KeyUsage{
+	"digital_signature":  x509.KeyUsageDigitalSignature,
+	"content_commitment": x509.KeyUsageContentCommitment,
+	"key_encipherment":   x509.KeyUsageKeyEncipherment,
+	"data_encipherment":  x509.KeyUsageDataEncipherment,
+	"key_agreement":      x509.KeyUsageKeyAgreement,
+	"cert_sign":          x509.KeyUsageCertSign,
+	"crl_sign":           x509.KeyUsageCRLSign,
+	"encipher_only":      x509.KeyUsageEncipherOnly,
+	"decipher_only":      x509.KeyUsageDecipherOnly,
 }
 
 func NewCertificateGenerator(loader CertsLoader) CertificateGenerator {
 	return CertificateGenerator{loader: loader}
 }
 
+// NewCertificateGeneratorWithConfig returns a generator that embeds the
+// configured revocation endpoints into every certificate signed by a stored
+// CA. The CRL distribution point gets the CA name in the 'ca' query parameter.
+func NewCertificateGeneratorWithConfig(loader CertsLoader, config config.GeneratorsConfig) CertificateGenerator {
+	return CertificateGenerator{loader: loader, config: config}
+}
+
 func (cfg CertificateGenerator) Generate(parameters interface{}) (interface{}, error) {
 	var params certParams
 	err := objToStruct(parameters, &params, supportedCertParameters)
@@ -57,7 +114,58 @@ func (cfg CertificateGenerator) Generate(parameters interface{}) (interface{}, e
 		return nil, errors.WrapError(err, "Failed to generate certificate, parameters are invalid")
 	}
 
-	return cfg.generateCertificate(params)
+	return cfg.generateCertificate(params, nil)
+}
+
+// GenerateFromPrevious issues a new certificate for the private key of the
+// previous version when 'reuse_key' is set, and a new key otherwise.
+func (cfg CertificateGenerator) GenerateFromPrevious(parameters interface{}, previousValue string) (interface{}, error) {
+	var params certParams
+	err := objToStruct(parameters, &params, supportedCertParameters)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate certificate, parameters are invalid")
+	}
+
+	if !params.ReuseKey {
+		return cfg.generateCertificate(params, nil)
+	}
+
+	privateKey, err := parsePreviousPrivateKey(previousValue)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to reuse key of previous certificate")
+	}
+
+	return cfg.generateCertificate(params, privateKey)
+}
+
+func parsePreviousPrivateKey(previousValue string) (*rsa.PrivateKey, error) {
+	var certContainer struct {
+		Value struct {
+			PrivateKey string `json:"private_key"`
+		} `json:"value"`
+	}
+
+	err := json.Unmarshal([]byte(previousValue), &certContainer)
+	if err != nil || certContainer.Value.PrivateKey == "" {
+		return nil, errors.Error("Previous value does not contain a private key")
+	}
+
+	block, _ := pem.Decode([]byte(certContainer.Value.PrivateKey))
+	if block == nil {
+		return nil, errors.Error("Previous private key is not PEM encoded")
+	}
+
+	privateKey, err := ParsePrivateKeyPEMBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.Error("Previous private key is not an RSA key")
+	}
+
+	return rsaKey, nil
 }
 
 func (cfg CertificateGenerator) bigIntHash(n *big.Int) []byte {
@@ -66,12 +174,15 @@ func (cfg CertificateGenerator) bigIntHash(n *big.Int) []byte {
 	return h.Sum(nil)
 }
 
-func (cfg CertificateGenerator) generateCertificate(cParams certParams) (CertResponse, error) {
+func (cfg CertificateGenerator) generateCertificate(cParams certParams, privateKey *rsa.PrivateKey) (CertResponse, error) {
 	var certResponse CertResponse
+	var err error
 
-	privateKey, err := rsa.GenerateKey(rand.Reader, 3072)
-	if err != nil {
-		return certResponse, errors.WrapError(err, "Generating Key")
+	if privateKey == nil {
+		privateKey, err = rsa.GenerateKey(rand.Reader, 3072)
+		if err != nil {
+			return certResponse, errors.WrapError(err, "Generating Key")
+		}
 	}
 
 	certTemplate, err := generateCertTemplate(cParams)
@@ -80,9 +191,10 @@ func (cfg CertificateGenerator) generateCertificate(cParams certParams) (CertRes
 	}
 
 	var certificateRaw []byte
-	var rootCARaw []byte
 	var rootCA *x509.Certificate
 	var rootPKey *rsa.PrivateKey
+	var caBundle string
+	var chain string
 
 	if cParams.CAName != "" {
 		if cfg.loader == nil {
@@ -92,12 +204,32 @@ func (cfg CertificateGenerator) generateCertificate(cParams certParams) (CertRes
 		if err != nil {
 			return certResponse, errors.WrapError(err, "Loading certificates")
 		}
+
+		chain, err = cfg.loader.LoadChain(cParams.CAName)
+		if err != nil {
+			return certResponse, errors.WrapError(err, "Loading certificate chain")
+		}
+
+		caBundle, err = cfg.loader.LoadCABundle(cParams.CAName)
+		if err != nil {
+			return certResponse, errors.WrapError(err, "Loading CA bundle")
+		}
 	}
 
 	certTemplate.SubjectKeyId = cfg.bigIntHash(privateKey.N)
 
+	if cParams.CAName != "" && cfg.config.CRLDistributionPointURL != "" {
+		certTemplate.CRLDistributionPoints = []string{cfg.config.CRLDistributionPointURL + "?ca=" + url.QueryEscape(cParams.CAName)}
+	}
+
+	if cParams.CAName != "" && cfg.config.OCSPServerURL != "" {
+		certTemplate.OCSPServer = []string{cfg.config.OCSPServerURL}
+	}
+
 	if cParams.IsCA {
-		certTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		if certTemplate.KeyUsage == 0 {
+			certTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		}
 
 		signingKey := privateKey
 		signingCA := &certTemplate
@@ -113,61 +245,136 @@ func (cfg CertificateGenerator) generateCertificate(cParams certParams) (CertRes
 			return certResponse, errors.WrapError(err, "Generating CA certificate")
 		}
 
-		if cParams.CAName != "" {
-			rootCARaw = rootCA.Raw
-		} else {
-			rootCARaw = certificateRaw
+		if cParams.CAName == "" {
+			caBundle = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateRaw}))
+			chain = caBundle
 		}
 	} else {
 		if cParams.CAName == "" {
 			return certResponse, errors.Error("Missing required CA name")
 		}
-		certTemplate.KeyUsage = x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature
+		if certTemplate.KeyUsage == 0 {
+			certTemplate.KeyUsage = x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature
+		}
 
 		certTemplate.AuthorityKeyId = rootCA.SubjectKeyId
 
-		extKeyUsages := certTemplate.ExtKeyUsage
-		if len(cParams.ExtKeyUsage) != 0 {
-			for _, extKeyUsage := range cParams.ExtKeyUsage {
-				switch extKeyUsage {
-				case "client_auth":
-					extKeyUsages = append(extKeyUsages, x509.ExtKeyUsageClientAuth)
-				case "server_auth":
-					extKeyUsages = append(extKeyUsages, x509.ExtKeyUsageServerAuth)
-				default:
-					return certResponse, errors.Errorf("Unsupported extended key usage value: %s", extKeyUsage)
-				}
-			}
-		} else {
-			extKeyUsages = append(extKeyUsages, x509.ExtKeyUsageServerAuth)
+		extKeyUsages, err := parseExtKeyUsages(cParams.ExtKeyUsage)
+		if err != nil {
+			return certResponse, err
 		}
 
 		certTemplate.ExtKeyUsage = extKeyUsages
 
-		for _, altName := range cParams.AlternativeNames {
-			possibleIP := net.ParseIP(altName)
-			if possibleIP == nil {
-				certTemplate.DNSNames = append(certTemplate.DNSNames, altName)
-			} else {
-				certTemplate.IPAddresses = append(certTemplate.IPAddresses, possibleIP)
-			}
-		}
-
 		certificateRaw, err = x509.CreateCertificate(rand.Reader, &certTemplate, rootCA, &privateKey.PublicKey, rootPKey)
 		if err != nil {
 			return certResponse, errors.WrapError(err, "Generating certificate")
 		}
-		rootCARaw = rootCA.Raw
 	}
 
-	return generateCertResponse(privateKey, certificateRaw, rootCARaw), nil
+	return generateCertResponse(privateKey, certificateRaw, caBundle, chain), nil
 }
 
-func generateCertTemplate(cParams certParams) (x509.Certificate, error) {
+func parseExtKeyUsages(values []string) ([]x509.ExtKeyUsage, error) {
+	if len(values) == 0 {
+		return []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, nil
+	}
+
+	var extKeyUsages []x509.ExtKeyUsage
+	for _, extKeyUsage := range values {
+		switch extKeyUsage {
+		case "client_auth":
+			extKeyUsages = append(extKeyUsages, x509.ExtKeyUsageClientAuth)
+		case "server_auth":
+			extKeyUsages = append(extKeyUsages, x509.ExtKeyUsageServerAuth)
+		case "ocsp_signing":
+			extKeyUsages = append(extKeyUsages, x509.ExtKeyUsageOCSPSigning)
+		default:
+			return nil, errors.Errorf("Unsupported extended key usage value: %s", extKeyUsage)
+		}
+	}
+
+	return extKeyUsages, nil
+}
+
+func parseKeyUsages(values []string) (x509.KeyUsage, error) {
+	var keyUsage x509.KeyUsage
+	for _, value := range values {
+		usage, ok := keyUsages[value]
+		if !ok {
+			return 0, errors.Errorf("Unsupported key usage value: %s", value)
+		}
+		keyUsage |= usage
+	}
+
+	return keyUsage, nil
+}
+
+func parseIPRanges(param string, values []string) ([]*net.IPNet, error) {
+	var ipRanges []*net.IPNet
+	for _, value := range values {
+		_, ipRange, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, errors.Errorf("Failed to generate certificate, '%s' must contain CIDR ranges: %s", param, value)
+		}
+		ipRanges = append(ipRanges, ipRange)
+	}
+
+	return ipRanges, nil
+}
+
+// parseURISAN parses a URI subject alternative name. SPIFFE IDs are
+// additionally checked against the SPIFFE ID specification: a lowercase
+// trust domain, no empty path segments, and no port, user info, query or
+// fragment.
+func parseURISAN(value string) (*url.URL, error) {
+	uri, err := url.Parse(value)
+	if err != nil || uri.Scheme == "" || (uri.Host == "" && uri.Opaque == "") {
+		return nil, errors.Errorf("Failed to generate certificate, invalid URI SAN: %s", value)
+	}
+
+	if uri.Scheme != "spiffe" {
+		return uri, nil
+	}
+
+	if !spiffeTrustDomain.MatchString(uri.Host) || uri.User != nil || uri.RawQuery != "" || uri.Fragment != "" {
+		return nil, errors.Errorf("Failed to generate certificate, invalid SPIFFE ID: %s", value)
+	}
+
+	if uri.Path != "" {
+		for _, segment := range strings.Split(strings.TrimPrefix(uri.Path, "/"), "/") {
+			if segment == "" || segment == "." || segment == ".." {
+				return nil, errors.Errorf("Failed to generate certificate, invalid SPIFFE ID: %s", value)
+			}
+		}
+	}
+
+	return uri, nil
+}
+
+func validateEmailSAN(value string) error {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value || address.Name != "" {
+		return errors.Errorf("Failed to generate certificate, invalid email SAN: %s", value)
+	}
+
+	return nil
+}
+
+func generateSerialNumber() (*big.Int, error) {
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
 	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
 	if err != nil {
-		return x509.Certificate{}, errors.WrapError(err, "Generating Serial Number")
+		return nil, errors.WrapError(err, "Generating Serial Number")
+	}
+
+	return serialNumber, nil
+}
+
+func generateCertTemplate(cParams certParams) (x509.Certificate, error) {
+	serialNumber, err := generateSerialNumber()
+	if err != nil {
+		return x509.Certificate{}, err
 	}
 
 	now := time.Now()
@@ -177,37 +384,133 @@ func generateCertTemplate(cParams certParams) (x509.Certificate, error) {
 		notAfter = now.Add(time.Duration(cParams.Duration*24) * time.Hour)
 	}
 
+	country := cParams.Country
+	if country == "" {
+		country = "USA"
+	} else if len(country) != 2 {
+		return x509.Certificate{}, errors.Error("Failed to generate certificate, 'country' must be a two letter ISO 3166 code")
+	}
+
 	var organizations []string
-	if cParams.Organization == "" {
+	if cParams.Organization != "" {
+		organizations = append(organizations, cParams.Organization)
+	}
+	organizations = append(organizations, cParams.Organizations...)
+	if len(organizations) == 0 {
 		organizations = []string{"Cloud Foundry"}
-	} else {
-		organizations = []string{cParams.Organization}
+	}
+
+	subject := pkix.Name{
+		Country:            []string{country},
+		Organization:       organizations,
+		OrganizationalUnit: cParams.OrganizationalUnits,
+		CommonName:         cParams.CommonName,
+	}
+	if cParams.State != "" {
+		subject.Province = []string{cParams.State}
+	}
+	if cParams.Locality != "" {
+		subject.Locality = []string{cParams.Locality}
+	}
+
+	keyUsage, err := parseKeyUsages(cParams.KeyUsage)
+	if err != nil {
+		return x509.Certificate{}, err
 	}
 
 	template := x509.Certificate{
-		SerialNumber: serialNumber,
-		Subject: pkix.Name{
-			Country:      []string{"USA"},
-			Organization: organizations,
-			CommonName:   cParams.CommonName,
-		},
+		SerialNumber:          serialNumber,
+		Subject:               subject,
 		NotBefore:             now,
 		NotAfter:              notAfter,
+		KeyUsage:              keyUsage,
 		BasicConstraintsValid: true,
 		IsCA:                  cParams.IsCA,
 	}
+
+	uriSANs := append([]string{}, cParams.URISANs...)
+	emailSANs := append([]string{}, cParams.EmailSANs...)
+
+	for _, altName := range cParams.AlternativeNames {
+		if possibleIP := net.ParseIP(altName); possibleIP != nil {
+			template.IPAddresses = append(template.IPAddresses, possibleIP)
+		} else if strings.Contains(altName, "://") {
+			uriSANs = append(uriSANs, altName)
+		} else if strings.Contains(altName, "@") {
+			emailSANs = append(emailSANs, altName)
+		} else {
+			template.DNSNames = append(template.DNSNames, altName)
+		}
+	}
+
+	for _, uriSAN := range uriSANs {
+		uri, err := parseURISAN(uriSAN)
+		if err != nil {
+			return x509.Certificate{}, err
+		}
+		template.URIs = append(template.URIs, uri)
+	}
+
+	for _, emailSAN := range emailSANs {
+		err := validateEmailSAN(emailSAN)
+		if err != nil {
+			return x509.Certificate{}, err
+		}
+		template.EmailAddresses = append(template.EmailAddresses, emailSAN)
+	}
+
+	hasNameConstraints := len(cParams.PermittedDNSDomains) != 0 || len(cParams.ExcludedDNSDomains) != 0 ||
+		len(cParams.PermittedIPRanges) != 0 || len(cParams.ExcludedIPRanges) != 0
+
+	if !cParams.IsCA {
+		if cParams.MaxPathLen != nil {
+			return x509.Certificate{}, errors.Error("Failed to generate certificate, 'max_path_len' is only supported for CA certificates")
+		}
+		if hasNameConstraints {
+			return x509.Certificate{}, errors.Error("Failed to generate certificate, name constraints are only supported for CA certificates")
+		}
+		return template, nil
+	}
+
+	if cParams.MaxPathLen != nil {
+		if *cParams.MaxPathLen < 0 {
+			return x509.Certificate{}, errors.Error("Failed to generate certificate, 'max_path_len' param cannot be negative")
+		}
+		template.MaxPathLen = *cParams.MaxPathLen
+		template.MaxPathLenZero = *cParams.MaxPathLen == 0
+	}
+
+	if hasNameConstraints {
+		template.PermittedDNSDomainsCritical = true
+		template.PermittedDNSDomains = cParams.PermittedDNSDomains
+		template.ExcludedDNSDomains = cParams.ExcludedDNSDomains
+
+		template.PermittedIPRanges, err = parseIPRanges("permitted_ip_ranges", cParams.PermittedIPRanges)
+		if err != nil {
+			return x509.Certificate{}, err
+		}
+
+		template.ExcludedIPRanges, err = parseIPRanges("excluded_ip_ranges", cParams.ExcludedIPRanges)
+		if err != nil {
+			return x509.Certificate{}, err
+		}
+	}
+
 	return template, nil
 }
 
-func generateCertResponse(privateKey *rsa.PrivateKey, certificateRaw, rootCARaw []byte) CertResponse {
+// generateCertResponse builds the response for a generated certificate. The
+// chain holds the PEM encoded issuers, starting with the signing CA and
+// ending with the root.
+func generateCertResponse(privateKey *rsa.PrivateKey, certificateRaw []byte, caBundle string, chain string) CertResponse {
 	encodedCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateRaw})
 	encodedPrivatekey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
-	encodedRootCACert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootCARaw})
 
 	certResponse := CertResponse{
 		Certificate: string(encodedCert),
 		PrivateKey:  string(encodedPrivatekey),
-		CA:          string(encodedRootCACert),
+		CA:          caBundle,
+		Chain:       chain,
 	}
 
 	return certResponse
@@ -4,9 +4,12 @@ import (
 	. "github.com/shono09835/config-server/types"
 
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"time"
 
+	"github.com/shono09835/config-server/config"
 	"github.com/shono09835/config-server/types/typesfakes"
 
 	"strings"
@@ -75,6 +78,8 @@ sHx2rlaLkmSreYJsmVaiSp0E9lhdympuDF+WKRolkQ==
 		key, _ := x509.ParsePKCS1PrivateKey(kpb.Bytes)
 
 		fakeLoader.LoadCertsReturns(fakeRootCA, key, nil)
+		fakeLoader.LoadChainReturns("fake-chain", nil)
+		fakeLoader.LoadCABundleReturns(mockCertValue+"\n", nil)
 	})
 
 	Describe("Generate", func() {
@@ -130,6 +135,10 @@ sHx2rlaLkmSreYJsmVaiSp0E9lhdympuDF+WKRolkQ==
 						Expect(strings.Trim(certResp.CA, "\n")).To(Equal(strings.Trim(certResp.Certificate, "\n")))
 					})
 
+					It("sets the chain to itself", func() {
+						Expect(certResp.Chain).To(Equal(certResp.Certificate))
+					})
+
 					It("generates a root CA", func() {
 						Expect(certificate.IsCA).To(BeTrue())
 					})
@@ -186,6 +195,11 @@ sHx2rlaLkmSreYJsmVaiSp0E9lhdympuDF+WKRolkQ==
 						Expect(strings.Trim(certResp.CA, "\n")).To(Equal(mockCertValue))
 					})
 
+					It("sets the chain to the signing CA's chain", func() {
+						Expect(fakeLoader.LoadChainArgsForCall(0)).To(Equal("smurf-cert"))
+						Expect(certResp.Chain).To(Equal("fake-chain"))
+					})
+
 					It("sets KeyUsage and ExtKeyUsage", func() {
 						Expect(certificate.KeyUsage).To(Equal(x509.KeyUsageCertSign | x509.KeyUsageCRLSign))
 						Expect(certificate.ExtKeyUsage).To(BeEmpty())
@@ -350,6 +364,33 @@ sHx2rlaLkmSreYJsmVaiSp0E9lhdympuDF+WKRolkQ==
 						Expect(strings.Trim(certResp.CA, "\n")).To(Equal(mockCertValue))
 					})
 
+					It("sets the chain to the signing CA's chain", func() {
+						certResp := getCertResp(generator, params)
+						Expect(fakeLoader.LoadChainArgsForCall(0)).To(Equal("smurf-ca"))
+						Expect(certResp.Chain).To(Equal("fake-chain"))
+					})
+
+					It("returns an error when the chain cannot be loaded", func() {
+						fakeLoader.LoadChainReturns("", errors.New("fake-error"))
+
+						_, err := generator.Generate(params)
+						Expect(err).To(MatchError("Loading certificate chain: fake-error"))
+					})
+
+					It("returns an error when the CA bundle cannot be loaded", func() {
+						fakeLoader.LoadCABundleReturns("", errors.New("fake-error"))
+
+						_, err := generator.Generate(params)
+						Expect(err).To(MatchError("Loading CA bundle: fake-error"))
+					})
+
+					It("sets the ca to the loaded CA bundle", func() {
+						fakeLoader.LoadCABundleReturns("fake-bundle", nil)
+
+						certResp := getCertResp(generator, params)
+						Expect(certResp.CA).To(Equal("fake-bundle"))
+					})
+
 					It("set the AKI as the CA's SKI", func() {
 						certResp := getCertResp(generator, params)
 						certificate, _ := parseCertString(certResp.Certificate)
@@ -456,6 +497,164 @@ sHx2rlaLkmSreYJsmVaiSp0E9lhdympuDF+WKRolkQ==
 			})
 		})
 
+		Context("when passed subject and extension parameters", func() {
+			It("sets the subject fields", func() {
+				certResp := getCertResp(generator, map[interface{}]interface{}{
+					"is_ca":                true,
+					"common_name":          "bosh.io",
+					"country":              "DE",
+					"state":                "Berlin",
+					"locality":             "Berlin",
+					"organization":         "Org A",
+					"organizations":        []string{"Org B"},
+					"organizational_units": []string{"Unit A", "Unit B"},
+				})
+				certificate, _ := parseCertString(certResp.Certificate)
+
+				Expect(certificate.Subject.Country).To(Equal([]string{"DE"}))
+				Expect(certificate.Subject.Province).To(Equal([]string{"Berlin"}))
+				Expect(certificate.Subject.Locality).To(Equal([]string{"Berlin"}))
+				Expect(certificate.Subject.Organization).To(Equal([]string{"Org A", "Org B"}))
+				Expect(certificate.Subject.OrganizationalUnit).To(Equal([]string{"Unit A", "Unit B"}))
+			})
+
+			It("uses the explicit key usages", func() {
+				certResp := getCertResp(generator, map[interface{}]interface{}{
+					"ca":        "smurf-ca",
+					"key_usage": []string{"digital_signature", "key_agreement"},
+				})
+				certificate, _ := parseCertString(certResp.Certificate)
+
+				Expect(certificate.KeyUsage).To(Equal(x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement))
+			})
+
+			It("applies alternative names to CA certificates", func() {
+				certResp := getCertResp(generator, map[interface{}]interface{}{
+					"is_ca":             true,
+					"alternative_names": []string{"ca.bosh.io", "10.0.0.1"},
+				})
+				certificate, _ := parseCertString(certResp.Certificate)
+
+				Expect(certificate.DNSNames).To(Equal([]string{"ca.bosh.io"}))
+				Expect(certificate.IPAddresses[0].String()).To(Equal("10.0.0.1"))
+			})
+
+			It("sets max_path_len on CA certificates", func() {
+				certResp := getCertResp(generator, map[interface{}]interface{}{"is_ca": true, "max_path_len": 0})
+				certificate, _ := parseCertString(certResp.Certificate)
+
+				Expect(certificate.MaxPathLen).To(Equal(0))
+				Expect(certificate.MaxPathLenZero).To(BeTrue())
+
+				certResp = getCertResp(generator, map[interface{}]interface{}{"is_ca": true, "max_path_len": 2})
+				certificate, _ = parseCertString(certResp.Certificate)
+
+				Expect(certificate.MaxPathLen).To(Equal(2))
+			})
+
+			It("sets critical name constraints on CA certificates", func() {
+				certResp := getCertResp(generator, map[interface{}]interface{}{
+					"is_ca":                 true,
+					"permitted_dns_domains": []string{".bosh.io"},
+					"excluded_dns_domains":  []string{"evil.bosh.io"},
+					"permitted_ip_ranges":   []string{"10.0.0.0/8"},
+					"excluded_ip_ranges":    []string{"10.1.0.0/16"},
+				})
+				certificate, _ := parseCertString(certResp.Certificate)
+
+				Expect(certificate.PermittedDNSDomainsCritical).To(BeTrue())
+				Expect(certificate.PermittedDNSDomains).To(Equal([]string{".bosh.io"}))
+				Expect(certificate.ExcludedDNSDomains).To(Equal([]string{"evil.bosh.io"}))
+				Expect(certificate.PermittedIPRanges[0].String()).To(Equal("10.0.0.0/8"))
+				Expect(certificate.ExcludedIPRanges[0].String()).To(Equal("10.1.0.0/16"))
+			})
+
+			It("recognizes URI and email alternative names", func() {
+				certResp := getCertResp(generator, map[interface{}]interface{}{
+					"ca":                "smurf-ca",
+					"alternative_names": []string{"bosh.io", "spiffe://example.org/ns/default/sa/web", "admin@bosh.io"},
+				})
+				certificate, _ := parseCertString(certResp.Certificate)
+
+				Expect(certificate.DNSNames).To(Equal([]string{"bosh.io"}))
+				Expect(certificate.URIs).To(HaveLen(1))
+				Expect(certificate.URIs[0].String()).To(Equal("spiffe://example.org/ns/default/sa/web"))
+				Expect(certificate.EmailAddresses).To(Equal([]string{"admin@bosh.io"}))
+			})
+
+			It("uses explicit uri_sans and email_sans", func() {
+				certResp := getCertResp(generator, map[interface{}]interface{}{
+					"ca":         "smurf-ca",
+					"uri_sans":   []string{"spiffe://example.org/web", "https://bosh.io/agent"},
+					"email_sans": []string{"admin@bosh.io"},
+				})
+				certificate, _ := parseCertString(certResp.Certificate)
+
+				Expect(certificate.URIs).To(HaveLen(2))
+				Expect(certificate.URIs[1].String()).To(Equal("https://bosh.io/agent"))
+				Expect(certificate.EmailAddresses).To(Equal([]string{"admin@bosh.io"}))
+			})
+
+			It("returns an error for invalid SPIFFE IDs", func() {
+				for _, spiffeID := range []string{"spiffe://Example.org/web", "spiffe://example.org//web", "spiffe://example.org/web?x=1", "spiffe://example.org:8080/web"} {
+					_, err := generator.Generate(map[interface{}]interface{}{"ca": "smurf-ca", "uri_sans": []string{spiffeID}})
+					Expect(err).To(MatchError("Failed to generate certificate, invalid SPIFFE ID: " + spiffeID))
+				}
+			})
+
+			It("returns an error for invalid URI SANs", func() {
+				_, err := generator.Generate(map[interface{}]interface{}{"ca": "smurf-ca", "uri_sans": []string{"not-a-uri"}})
+				Expect(err).To(MatchError("Failed to generate certificate, invalid URI SAN: not-a-uri"))
+			})
+
+			It("returns an error for invalid email SANs", func() {
+				_, err := generator.Generate(map[interface{}]interface{}{"ca": "smurf-ca", "alternative_names": []string{"admin@@bosh.io"}})
+				Expect(err).To(MatchError("Failed to generate certificate, invalid email SAN: admin@@bosh.io"))
+			})
+
+			It("embeds the revocation endpoints of the signing CA when configured", func() {
+				generator = NewCertificateGeneratorWithConfig(fakeLoader, config.GeneratorsConfig{
+					CRLDistributionPointURL: "https://config-server:8080/v1/crl",
+					OCSPServerURL:           "https://config-server:8080/v1/ocsp",
+				})
+
+				certResp := getCertResp(generator, map[interface{}]interface{}{"ca": "smurf/ca"})
+				certificate, _ := parseCertString(certResp.Certificate)
+				Expect(certificate.CRLDistributionPoints).To(Equal([]string{"https://config-server:8080/v1/crl?ca=smurf%2Fca"}))
+				Expect(certificate.OCSPServer).To(Equal([]string{"https://config-server:8080/v1/ocsp"}))
+
+				certResp = getCertResp(generator, map[interface{}]interface{}{"is_ca": true})
+				certificate, _ = parseCertString(certResp.Certificate)
+				Expect(certificate.CRLDistributionPoints).To(BeEmpty())
+				Expect(certificate.OCSPServer).To(BeEmpty())
+			})
+
+			It("returns an error for unsupported key usages", func() {
+				_, err := generator.Generate(map[interface{}]interface{}{"is_ca": true, "key_usage": []string{"everything"}})
+				Expect(err).To(MatchError("Unsupported key usage value: everything"))
+			})
+
+			It("returns an error for invalid country codes", func() {
+				_, err := generator.Generate(map[interface{}]interface{}{"is_ca": true, "country": "Germany"})
+				Expect(err).To(MatchError("Failed to generate certificate, 'country' must be a two letter ISO 3166 code"))
+			})
+
+			It("returns an error for max_path_len on non-CA certificates", func() {
+				_, err := generator.Generate(map[interface{}]interface{}{"ca": "smurf-ca", "max_path_len": 1})
+				Expect(err).To(MatchError("Failed to generate certificate, 'max_path_len' is only supported for CA certificates"))
+			})
+
+			It("returns an error for name constraints on non-CA certificates", func() {
+				_, err := generator.Generate(map[interface{}]interface{}{"ca": "smurf-ca", "permitted_dns_domains": []string{".bosh.io"}})
+				Expect(err).To(MatchError("Failed to generate certificate, name constraints are only supported for CA certificates"))
+			})
+
+			It("returns an error for invalid IP ranges", func() {
+				_, err := generator.Generate(map[interface{}]interface{}{"is_ca": true, "permitted_ip_ranges": []string{"10.0.0.1"}})
+				Expect(err).To(MatchError("Failed to generate certificate, 'permitted_ip_ranges' must contain CIDR ranges: 10.0.0.1"))
+			})
+		})
+
 		Context("when passed parameters use unsupported keys", func() {
 			var params map[interface{}]interface{}
 			BeforeEach(func() {
@@ -474,4 +673,59 @@ sHx2rlaLkmSreYJsmVaiSp0E9lhdympuDF+WKRolkQ==
 			})
 		})
 	})
+
+	Describe("GenerateFromPrevious", func() {
+		var (
+			previous      CertResponse
+			previousValue string
+		)
+
+		BeforeEach(func() {
+			previous = getCertResp(generator, map[interface{}]interface{}{"common_name": "bosh.io", "ca": "smurf/ca"})
+
+			bytes, _ := json.Marshal(map[string]interface{}{"value": previous})
+			previousValue = string(bytes)
+		})
+
+		It("issues a new certificate for the previous private key when reuse_key is set", func() {
+			params := map[interface{}]interface{}{"common_name": "bosh.io", "ca": "smurf/ca", "reuse_key": true}
+
+			certResp, err := generator.(PreviousValueGenerator).GenerateFromPrevious(params, previousValue)
+			Expect(err).ToNot(HaveOccurred())
+
+			renewed := certResp.(CertResponse)
+			Expect(renewed.PrivateKey).To(Equal(previous.PrivateKey))
+
+			previousCert, _ := parseCertString(previous.Certificate)
+			renewedCert, _ := parseCertString(renewed.Certificate)
+			Expect(renewedCert.SerialNumber).ToNot(Equal(previousCert.SerialNumber))
+			Expect(renewedCert.PublicKey).To(Equal(previousCert.PublicKey))
+			Expect(renewedCert.SubjectKeyId).To(Equal(previousCert.SubjectKeyId))
+		})
+
+		It("reuses the key of CA certificates", func() {
+			caResp := getCertResp(generator, map[interface{}]interface{}{"common_name": "my-ca", "is_ca": true})
+			bytes, _ := json.Marshal(map[string]interface{}{"value": caResp})
+
+			certResp, err := generator.(PreviousValueGenerator).GenerateFromPrevious(map[interface{}]interface{}{"common_name": "my-ca", "is_ca": true, "reuse_key": true}, string(bytes))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(certResp.(CertResponse).PrivateKey).To(Equal(caResp.PrivateKey))
+			Expect(certResp.(CertResponse).Certificate).ToNot(Equal(caResp.Certificate))
+		})
+
+		It("generates a new private key when reuse_key is not set", func() {
+			params := map[interface{}]interface{}{"common_name": "bosh.io", "ca": "smurf/ca"}
+
+			certResp, err := generator.(PreviousValueGenerator).GenerateFromPrevious(params, previousValue)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(certResp.(CertResponse).PrivateKey).ToNot(Equal(previous.PrivateKey))
+		})
+
+		It("returns an error when the previous value has no private key", func() {
+			params := map[interface{}]interface{}{"common_name": "bosh.io", "ca": "smurf/ca", "reuse_key": true}
+
+			_, err := generator.(PreviousValueGenerator).GenerateFromPrevious(params, `{"value":"smurf"}`)
+			Expect(err).To(MatchError("Failed to reuse key of previous certificate: Previous value does not contain a private key"))
+		})
+	})
 })
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"crypto/x509"
+	"math"
+	"sort"
+)
+
+// CertificateParameters returns the generation parameters that reproduce
+// the subject, alternative names, usages, constraints and duration of a
+// certificate generated by CertificateGenerator. caName is the name of the
+// issuing CA, empty for self-signed certificates.
+func CertificateParameters(certificate *x509.Certificate, caName string) map[string]interface{} {
+	parameters := map[string]interface{}{
+		"common_name": certificate.Subject.CommonName,
+		"duration":    int64(math.Round(certificate.NotAfter.Sub(certificate.NotBefore).Hours() / 24)),
+	}
+
+	if caName != "" {
+		parameters["ca"] = caName
+	}
+
+	subject := certificate.Subject
+	if len(subject.Country) == 1 && len(subject.Country[0]) == 2 {
+		parameters["country"] = subject.Country[0]
+	}
+	if len(subject.Province) != 0 {
+		parameters["state"] = subject.Province[0]
+	}
+	if len(subject.Locality) != 0 {
+		parameters["locality"] = subject.Locality[0]
+	}
+	if len(subject.Organization) != 0 {
+		parameters["organizations"] = subject.Organization
+	}
+	if len(subject.OrganizationalUnit) != 0 {
+		parameters["organizational_units"] = subject.OrganizationalUnit
+	}
+
+	var alternativeNames []string
+	alternativeNames = append(alternativeNames, certificate.DNSNames...)
+	for _, ip := range certificate.IPAddresses {
+		alternativeNames = append(alternativeNames, ip.String())
+	}
+	if len(alternativeNames) != 0 {
+		parameters["alternative_names"] = alternativeNames
+	}
+
+	var uriSANs []string
+	for _, uri := range certificate.URIs {
+		uriSANs = append(uriSANs, uri.String())
+	}
+	if len(uriSANs) != 0 {
+		parameters["uri_sans"] = uriSANs
+	}
+	if len(certificate.EmailAddresses) != 0 {
+		parameters["email_sans"] = certificate.EmailAddresses
+	}
+
+	var keyUsageNames []string
+	for name, usage := range keyUsages {
+		if certificate.KeyUsage&usage != 0 {
+			keyUsageNames = append(keyUsageNames, name)
+		}
+	}
+	sort.Strings(keyUsageNames)
+	if len(keyUsageNames) != 0 {
+		parameters["key_usage"] = keyUsageNames
+	}
+
+	if !certificate.IsCA {
+		if extKeyUsageNames := extKeyUsageNames(certificate.ExtKeyUsage); len(extKeyUsageNames) != 0 {
+			parameters["extended_key_usage"] = extKeyUsageNames
+		}
+		return parameters
+	}
+
+	parameters["is_ca"] = true
+
+	if certificate.MaxPathLen > 0 || certificate.MaxPathLenZero {
+		parameters["max_path_len"] = certificate.MaxPathLen
+	}
+
+	if len(certificate.PermittedDNSDomains) != 0 {
+		parameters["permitted_dns_domains"] = certificate.PermittedDNSDomains
+	}
+	if len(certificate.ExcludedDNSDomains) != 0 {
+		parameters["excluded_dns_domains"] = certificate.ExcludedDNSDomains
+	}
+
+	var permittedIPRanges, excludedIPRanges []string
+	for _, ipRange := range certificate.PermittedIPRanges {
+		permittedIPRanges = append(permittedIPRanges, ipRange.String())
+	}
+	for _, ipRange := range certificate.ExcludedIPRanges {
+		excludedIPRanges = append(excludedIPRanges, ipRange.String())
+	}
+	if len(permittedIPRanges) != 0 {
+		parameters["permitted_ip_ranges"] = permittedIPRanges
+	}
+	if len(excludedIPRanges) != 0 {
+		parameters["excluded_ip_ranges"] = excludedIPRanges
+	}
+
+	return parameters
+}
+
+func extKeyUsageNames(extKeyUsages []x509.ExtKeyUsage) []string {
+	var names []string
+	for _, extKeyUsage := range extKeyUsages {
+		switch extKeyUsage {
+		case x509.ExtKeyUsageClientAuth:
+			names = append(names, "client_auth")
+		case x509.ExtKeyUsageServerAuth:
+			names = append(names, "server_auth")
+		case x509.ExtKeyUsageOCSPSigning:
+			names = append(names, "ocsp_signing")
+		}
+	}
+	return names
+}
@@ -0,0 +1,125 @@
+package types_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("CertificateParameters", func() {
+	notBefore := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	It("returns the parameters of a leaf certificate", func() {
+		spiffeID, _ := url.Parse("spiffe://example.org/service")
+
+		certificate := &x509.Certificate{
+			Subject: pkix.Name{
+				CommonName:         "server",
+				Country:            []string{"DE"},
+				Province:           []string{"Berlin"},
+				Locality:           []string{"Berlin"},
+				Organization:       []string{"org-1", "org-2"},
+				OrganizationalUnit: []string{"unit"},
+			},
+			NotBefore:      notBefore,
+			NotAfter:       notBefore.Add(30 * 24 * time.Hour),
+			DNSNames:       []string{"server.example.com"},
+			IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+			URIs:           []*url.URL{spiffeID},
+			EmailAddresses: []string{"ops@example.com"},
+			KeyUsage:       x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+
+		Expect(CertificateParameters(certificate, "my-ca")).To(Equal(map[string]interface{}{
+			"common_name":          "server",
+			"ca":                   "my-ca",
+			"duration":             int64(30),
+			"country":              "DE",
+			"state":                "Berlin",
+			"locality":             "Berlin",
+			"organizations":        []string{"org-1", "org-2"},
+			"organizational_units": []string{"unit"},
+			"alternative_names":    []string{"server.example.com", "10.0.0.1"},
+			"uri_sans":             []string{"spiffe://example.org/service"},
+			"email_sans":           []string{"ops@example.com"},
+			"key_usage":            []string{"digital_signature", "key_encipherment"},
+			"extended_key_usage":   []string{"server_auth", "client_auth"},
+		}))
+	})
+
+	It("omits the default country", func() {
+		certificate := &x509.Certificate{
+			Subject:   pkix.Name{CommonName: "server", Country: []string{"USA"}},
+			NotBefore: notBefore,
+			NotAfter:  notBefore.Add(24 * time.Hour),
+		}
+
+		Expect(CertificateParameters(certificate, "my-ca")).ToNot(HaveKey("country"))
+	})
+
+	It("returns the parameters of a CA certificate", func() {
+		_, permittedRange, _ := net.ParseCIDR("10.0.0.0/8")
+
+		certificate := &x509.Certificate{
+			Subject:             pkix.Name{CommonName: "my-ca"},
+			NotBefore:           notBefore,
+			NotAfter:            notBefore.Add(365 * 24 * time.Hour),
+			IsCA:                true,
+			KeyUsage:            x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+			ExtKeyUsage:         []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			MaxPathLen:          0,
+			MaxPathLenZero:      true,
+			PermittedDNSDomains: []string{"example.com"},
+			PermittedIPRanges:   []*net.IPNet{permittedRange},
+		}
+
+		Expect(CertificateParameters(certificate, "")).To(Equal(map[string]interface{}{
+			"common_name":           "my-ca",
+			"duration":              int64(365),
+			"is_ca":                 true,
+			"key_usage":             []string{"cert_sign", "crl_sign"},
+			"max_path_len":          0,
+			"permitted_dns_domains": []string{"example.com"},
+			"permitted_ip_ranges":   []string{"10.0.0.0/8"},
+		}))
+	})
+
+	It("returns parameters the certificate generator accepts", func() {
+		generator := NewCertificateGenerator(nil)
+		value, err := generator.Generate(map[string]interface{}{
+			"common_name":       "my-ca",
+			"is_ca":             true,
+			"duration":          10,
+			"organization":      "org",
+			"alternative_names": []string{"ca.example.com"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		block, _ := pem.Decode([]byte(value.(CertResponse).Certificate))
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		Expect(err).ToNot(HaveOccurred())
+
+		renewed, err := generator.Generate(CertificateParameters(certificate, ""))
+		Expect(err).ToNot(HaveOccurred())
+
+		block, _ = pem.Decode([]byte(renewed.(CertResponse).Certificate))
+		renewedCertificate, err := x509.ParseCertificate(block.Bytes)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(renewedCertificate.Subject.String()).To(Equal(certificate.Subject.String()))
+		Expect(renewedCertificate.DNSNames).To(Equal(certificate.DNSNames))
+		Expect(renewedCertificate.KeyUsage).To(Equal(certificate.KeyUsage))
+		Expect(renewedCertificate.IsCA).To(BeTrue())
+		Expect(renewedCertificate.MaxPathLen).To(Equal(certificate.MaxPathLen))
+		Expect(renewedCertificate.NotAfter.Sub(renewedCertificate.NotBefore)).To(Equal(certificate.NotAfter.Sub(certificate.NotBefore)))
+	})
+})
@@ -9,4 +9,8 @@ import (
 
 type CertsLoader interface {
 	LoadCerts(string) (*x509.Certificate, *rsa.PrivateKey, error)
+	LoadChain(string) (string, error)
+	// LoadCABundle returns the PEM encoded certificates of every version of
+	// the named CA that should be trusted, starting with the signing one.
+	LoadCABundle(string) (string, error)
 }
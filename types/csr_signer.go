@@ -0,0 +1,222 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const DefaultSignedCertificateDuration = 365
+
+type CSRSigner struct {
+	loader CertsLoader
+}
+
+type SignedCertificate struct {
+	Certificate string `json:"certificate" yaml:"certificate"`
+	CA          string `json:"ca"          yaml:"ca"`
+	Chain       string `json:"chain"       yaml:"chain"`
+}
+
+type signParams struct {
+	CAName       string   `yaml:"ca"`
+	Duration     int64    `yaml:"duration"`
+	ExtKeyUsage  []string `yaml:"extended_key_usage"`
+	AllowedNames []string `yaml:"allowed_names"`
+}
+
+var supportedSignParameters = []string{
+	"ca",
+	"duration",
+	"extended_key_usage",
+	"allowed_names",
+}
+
+func NewCSRSigner(loader CertsLoader) CSRSigner {
+	return CSRSigner{loader: loader}
+}
+
+// Sign issues a certificate for the PEM encoded CSR, signed by the CA named
+// in the parameters. Only the subject, public key and subject alternative
+// names are taken from the CSR; requested extensions are ignored.
+func (s CSRSigner) Sign(csrPEM string, parameters interface{}) (SignedCertificate, error) {
+	var params signParams
+	err := objToStruct(parameters, &params, supportedSignParameters)
+	if err != nil {
+		return SignedCertificate{}, errors.WrapError(err, "Failed to sign certificate, parameters are invalid")
+	}
+
+	if params.CAName == "" {
+		return SignedCertificate{}, errors.Error("Missing required CA name")
+	}
+
+	if params.Duration < 0 {
+		return SignedCertificate{}, errors.Error("Failed to sign certificate, 'duration' param cannot be negative")
+	}
+
+	if params.Duration == 0 {
+		params.Duration = DefaultSignedCertificateDuration
+	}
+
+	csr, err := parseCSR(csrPEM)
+	if err != nil {
+		return SignedCertificate{}, err
+	}
+
+	err = checkAllowedNames(csr, params.AllowedNames)
+	if err != nil {
+		return SignedCertificate{}, err
+	}
+
+	extKeyUsages, err := parseExtKeyUsages(params.ExtKeyUsage)
+	if err != nil {
+		return SignedCertificate{}, err
+	}
+
+	keyUsage := x509.KeyUsageDigitalSignature
+	switch csr.PublicKey.(type) {
+	case *rsa.PublicKey:
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+	default:
+		return SignedCertificate{}, errors.Error("Failed to sign certificate, unsupported CSR public key type")
+	}
+
+	if s.loader == nil {
+		panic("Expected CSRSigner to have Loader set")
+	}
+
+	caCert, caKey, err := s.loader.LoadCerts(params.CAName)
+	if err != nil {
+		return SignedCertificate{}, errors.WrapError(err, "Loading certificates")
+	}
+
+	if !caCert.IsCA {
+		return SignedCertificate{}, errors.Errorf("Certificate %s is not a CA", params.CAName)
+	}
+
+	chain, err := s.loader.LoadChain(params.CAName)
+	if err != nil {
+		return SignedCertificate{}, errors.WrapError(err, "Loading certificate chain")
+	}
+
+	caBundle, err := s.loader.LoadCABundle(params.CAName)
+	if err != nil {
+		return SignedCertificate{}, errors.WrapError(err, "Loading CA bundle")
+	}
+
+	serialNumber, err := generateSerialNumber()
+	if err != nil {
+		return SignedCertificate{}, err
+	}
+
+	now := time.Now()
+
+	template := x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               csr.Subject,
+		NotBefore:             now,
+		NotAfter:              now.Add(time.Duration(params.Duration*24) * time.Hour),
+		BasicConstraintsValid: true,
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           extKeyUsages,
+		AuthorityKeyId:        caCert.SubjectKeyId,
+		DNSNames:              csr.DNSNames,
+		IPAddresses:           csr.IPAddresses,
+		EmailAddresses:        csr.EmailAddresses,
+		URIs:                  csr.URIs,
+	}
+
+	certificateRaw, err := x509.CreateCertificate(rand.Reader, &template, caCert, csr.PublicKey, caKey)
+	if err != nil {
+		return SignedCertificate{}, errors.WrapError(err, "Signing certificate")
+	}
+
+	return SignedCertificate{
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateRaw})),
+		CA:          caBundle,
+		Chain:       chain,
+	}, nil
+}
+
+func parseCSR(csrPEM string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.Error("Failed to sign certificate, CSR must be a PEM encoded 'CERTIFICATE REQUEST'")
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to sign certificate, CSR is invalid")
+	}
+
+	err = csr.CheckSignature()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to sign certificate, CSR signature is invalid")
+	}
+
+	return csr, nil
+}
+
+// checkAllowedNames verifies the subject common name and every subject
+// alternative name in the CSR match one of the allowed names, since clients
+// may still match on the common name. A leading "*." in an allowed name
+// matches exactly one DNS label. An empty allow-list permits any name.
+func checkAllowedNames(csr *x509.CertificateRequest, allowedNames []string) error {
+	if len(allowedNames) == 0 {
+		return nil
+	}
+
+	var requestedNames []string
+	if csr.Subject.CommonName != "" {
+		requestedNames = append(requestedNames, csr.Subject.CommonName)
+	}
+	requestedNames = append(requestedNames, csr.DNSNames...)
+	requestedNames = append(requestedNames, csr.EmailAddresses...)
+	for _, ip := range csr.IPAddresses {
+		requestedNames = append(requestedNames, ip.String())
+	}
+	for _, uri := range csr.URIs {
+		requestedNames = append(requestedNames, uri.String())
+	}
+
+	for _, requestedName := range requestedNames {
+		allowed := false
+		for _, allowedName := range allowedNames {
+			if nameMatches(allowedName, requestedName) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return errors.Errorf("Failed to sign certificate, name '%s' is not in 'allowed_names'", requestedName)
+		}
+	}
+
+	return nil
+}
+
+func nameMatches(pattern, name string) bool {
+	pattern = strings.ToLower(pattern)
+	name = strings.ToLower(name)
+
+	if strings.HasPrefix(pattern, "*.") {
+		suffix := pattern[1:]
+		if !strings.HasSuffix(name, suffix) {
+			return false
+		}
+
+		label := strings.TrimSuffix(name, suffix)
+		return label != "" && !strings.Contains(label, ".")
+	}
+
+	return pattern == name
+}
@@ -0,0 +1,212 @@
+package types_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/types"
+	"github.com/shono09835/config-server/types/typesfakes"
+)
+
+func generateTestCA(isCA bool) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	cert, err := x509.ParseCertificate(raw)
+	Expect(err).ToNot(HaveOccurred())
+
+	return cert, key
+}
+
+func generateTestCSR(template x509.CertificateRequest) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	raw, err := x509.CreateCertificateRequest(rand.Reader, &template, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: raw}))
+}
+
+var _ = Describe("CSRSigner", func() {
+	var (
+		fakeLoader *typesfakes.FakeCertsLoader
+		signer     CSRSigner
+		caCert     *x509.Certificate
+		csr        string
+	)
+
+	BeforeEach(func() {
+		var caKey *rsa.PrivateKey
+		caCert, caKey = generateTestCA(true)
+
+		fakeLoader = new(typesfakes.FakeCertsLoader)
+		fakeLoader.LoadCertsReturns(caCert, caKey, nil)
+		fakeLoader.LoadChainReturns("fake-chain", nil)
+		fakeLoader.LoadCABundleReturns(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})), nil)
+
+		signer = NewCSRSigner(fakeLoader)
+
+		csr = generateTestCSR(x509.CertificateRequest{
+			Subject:     pkix.Name{CommonName: "app.example.com"},
+			DNSNames:    []string{"app.example.com"},
+			IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+		})
+	})
+
+	Describe("Sign", func() {
+		It("issues a certificate for the CSR signed by the CA", func() {
+			signed, err := signer.Sign(csr, map[string]interface{}{"ca": "my-ca"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeLoader.LoadCertsArgsForCall(0)).To(Equal("my-ca"))
+
+			cert, err := parseCertString(signed.Certificate)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cert.CheckSignatureFrom(caCert)).To(Succeed())
+			Expect(cert.Subject.CommonName).To(Equal("app.example.com"))
+			Expect(cert.DNSNames).To(Equal([]string{"app.example.com"}))
+			Expect(cert.IPAddresses[0].String()).To(Equal("10.0.0.1"))
+			Expect(cert.IsCA).To(BeFalse())
+			Expect(cert.KeyUsage).To(Equal(x509.KeyUsageDigitalSignature))
+			Expect(cert.ExtKeyUsage).To(Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}))
+			Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(365*24*time.Hour), time.Minute))
+
+			caFromResponse, err := parseCertString(signed.CA)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(caFromResponse.Equal(caCert)).To(BeTrue())
+			Expect(signed.Chain).To(Equal("fake-chain"))
+		})
+
+		It("uses the provided duration and extended key usages", func() {
+			signed, err := signer.Sign(csr, map[string]interface{}{
+				"ca":                 "my-ca",
+				"duration":           30,
+				"extended_key_usage": []interface{}{"client_auth"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			cert, err := parseCertString(signed.Certificate)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(30*24*time.Hour), time.Minute))
+			Expect(cert.ExtKeyUsage).To(Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}))
+		})
+
+		It("accepts names matching the allow-list", func() {
+			_, err := signer.Sign(csr, map[string]interface{}{
+				"ca":            "my-ca",
+				"allowed_names": []interface{}{"*.example.com", "10.0.0.1"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("rejects names missing from the allow-list", func() {
+			_, err := signer.Sign(csr, map[string]interface{}{
+				"ca":            "my-ca",
+				"allowed_names": []interface{}{"*.example.com"},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to sign certificate, name '10.0.0.1' is not in 'allowed_names'"))
+		})
+
+		It("rejects a common name missing from the allow-list", func() {
+			csr = generateTestCSR(x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "anything.example"},
+				DNSNames: []string{"app.example.com"},
+			})
+
+			_, err := signer.Sign(csr, map[string]interface{}{
+				"ca":            "my-ca",
+				"allowed_names": []interface{}{"*.example.com"},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to sign certificate, name 'anything.example' is not in 'allowed_names'"))
+		})
+
+		It("only lets wildcards match a single label", func() {
+			csr = generateTestCSR(x509.CertificateRequest{DNSNames: []string{"a.b.example.com"}})
+
+			_, err := signer.Sign(csr, map[string]interface{}{
+				"ca":            "my-ca",
+				"allowed_names": []interface{}{"*.example.com"},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to sign certificate, name 'a.b.example.com' is not in 'allowed_names'"))
+		})
+
+		It("errors when the CSR is not PEM encoded", func() {
+			_, err := signer.Sign("garbage", map[string]interface{}{"ca": "my-ca"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to sign certificate, CSR must be a PEM encoded 'CERTIFICATE REQUEST'"))
+		})
+
+		It("errors when the CSR signature is invalid", func() {
+			block, _ := pem.Decode([]byte(csr))
+			block.Bytes[len(block.Bytes)-1] ^= 0xff
+
+			_, err := signer.Sign(string(pem.EncodeToMemory(block)), map[string]interface{}{"ca": "my-ca"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("Failed to sign certificate"))
+		})
+
+		It("errors when the CA name is missing", func() {
+			_, err := signer.Sign(csr, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Missing required CA name"))
+		})
+
+		It("errors when the CA cannot be loaded", func() {
+			fakeLoader.LoadCertsReturns(nil, nil, errors.New("No certificate found"))
+
+			_, err := signer.Sign(csr, map[string]interface{}{"ca": "my-ca"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Loading certificates: No certificate found"))
+		})
+
+		It("errors when the loaded certificate is not a CA", func() {
+			cert, key := generateTestCA(false)
+			fakeLoader.LoadCertsReturns(cert, key, nil)
+
+			_, err := signer.Sign(csr, map[string]interface{}{"ca": "my-ca"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Certificate my-ca is not a CA"))
+		})
+
+		It("errors on unsupported extended key usages", func() {
+			_, err := signer.Sign(csr, map[string]interface{}{"ca": "my-ca", "extended_key_usage": []interface{}{"code_signing"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Unsupported extended key usage value: code_signing"))
+		})
+
+		It("errors on unknown parameters", func() {
+			_, err := signer.Sign(csr, map[string]interface{}{"ca": "my-ca", "common_name": "foo"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to sign certificate, parameters are invalid: Unsupported parameter 'common_name'"))
+		})
+	})
+})
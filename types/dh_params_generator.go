@@ -0,0 +1,267 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	DefaultDHParamsBits    = 2048
+	DefaultDHParamsTimeout = 30 * time.Second
+
+	dhParamsMinimumBits = 1024
+	dhParamsMaximumBits = 8192
+	dhParamsGenerator   = 2
+	dhParamsHeader      = "DH PARAMETERS"
+
+	// Number of odd primes used to sieve out candidates before running
+	// the expensive primality tests
+	dhParamsSievePrimes = 2048
+
+	// Generations cannot be cancelled, so only a few may run or wait to be
+	// collected at a time, and results that are not collected are dropped
+	dhParamsMaximumJobs = 4
+	dhParamsResultTTL   = 10 * time.Minute
+)
+
+var dhParamsSmallPrimes = generateSmallPrimes(dhParamsSievePrimes)
+
+type DHParamsGenerator struct {
+	jobs    *dhParamsJobs
+	timeout time.Duration
+}
+
+type dhParamsParams struct {
+	Bits int `yaml:"bits"`
+}
+
+var supportedDHParamsParams = []string{
+	"bits",
+}
+
+type dhParameters struct {
+	P *big.Int
+	G int
+}
+
+// dhParamsJobs keeps track of generations that outlived the request that
+// started them, so that a retried request for the same name picks up their
+// result instead of starting over. Jobs are keyed by name so that different
+// names never end up with the same prime. Results that are not collected
+// within dhParamsResultTTL are dropped.
+type dhParamsJobs struct {
+	lock    sync.Mutex
+	pending map[dhParamsJobKey]*dhParamsJob
+}
+
+type dhParamsJobKey struct {
+	name string
+	bits int
+}
+
+type dhParamsJob struct {
+	done       chan struct{}
+	params     string
+	err        error
+	finishedAt time.Time
+}
+
+// NewDHParamsGenerator returns a generator that waits at most timeout for
+// parameters to be generated. Generations are shared between calls to
+// GenerateForName on the returned generator that use the same name.
+func NewDHParamsGenerator(timeout time.Duration) DHParamsGenerator {
+	if timeout <= 0 {
+		timeout = DefaultDHParamsTimeout
+	}
+	return DHParamsGenerator{
+		jobs:    &dhParamsJobs{pending: map[dhParamsJobKey]*dhParamsJob{}},
+		timeout: timeout,
+	}
+}
+
+func (g DHParamsGenerator) Generate(parameters interface{}) (interface{}, error) {
+	return g.GenerateForName("", parameters)
+}
+
+func (g DHParamsGenerator) GenerateForName(name string, parameters interface{}) (interface{}, error) {
+	var params dhParamsParams
+	err := objToStruct(parameters, &params, supportedDHParamsParams)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate DH parameters, parameters are invalid")
+	}
+
+	if params.Bits == 0 {
+		params.Bits = DefaultDHParamsBits
+	}
+
+	if params.Bits < dhParamsMinimumBits {
+		return nil, errors.Errorf("Failed to generate DH parameters, 'bits' must be at least %d", dhParamsMinimumBits)
+	}
+
+	if params.Bits > dhParamsMaximumBits {
+		return nil, errors.Errorf("Failed to generate DH parameters, 'bits' must be at most %d", dhParamsMaximumBits)
+	}
+
+	key := dhParamsJobKey{name: name, bits: params.Bits}
+	job, started := g.jobs.start(key, time.Now())
+	if !started {
+		return nil, GenerationInProgressError{
+			Message: "Too many DH parameters are being generated, retry the request later",
+		}
+	}
+
+	select {
+	case <-job.done:
+		g.jobs.finish(key, job)
+		return job.params, job.err
+	case <-time.After(g.timeout):
+		return nil, GenerationInProgressError{
+			Message: fmt.Sprintf("Generating %d bit DH parameters is taking longer than %s, retry the request to collect the result", params.Bits, g.timeout),
+		}
+	}
+}
+
+// start returns the job for the key, starting it unless it is already
+// pending. It returns false when too many jobs are pending to start another.
+func (j *dhParamsJobs) start(key dhParamsJobKey, now time.Time) (*dhParamsJob, bool) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	for pendingKey, job := range j.pending {
+		if job.isFinished() && now.Sub(job.finishedAt) > dhParamsResultTTL {
+			delete(j.pending, pendingKey)
+		}
+	}
+
+	if job, found := j.pending[key]; found {
+		return job, true
+	}
+
+	if len(j.pending) >= dhParamsMaximumJobs {
+		return nil, false
+	}
+
+	job := &dhParamsJob{done: make(chan struct{})}
+	j.pending[key] = job
+
+	go func() {
+		defer close(job.done)
+		job.params, job.err = generateDHParams(key.bits)
+		job.finishedAt = time.Now()
+	}()
+
+	return job, true
+}
+
+func (j *dhParamsJobs) finish(key dhParamsJobKey, job *dhParamsJob) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.pending[key] == job {
+		delete(j.pending, key)
+	}
+}
+
+func (j *dhParamsJob) isFinished() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func generateDHParams(bits int) (string, error) {
+	prime, err := generateSafePrime(bits)
+	if err != nil {
+		return "", errors.WrapError(err, "Generating safe prime")
+	}
+
+	der, err := asn1.Marshal(dhParameters{P: prime, G: dhParamsGenerator})
+	if err != nil {
+		return "", errors.WrapError(err, "Marshalling DH parameters")
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: dhParamsHeader, Bytes: der})), nil
+}
+
+// generateSafePrime returns a prime p = 2q+1 with q prime. Like OpenSSL it
+// only accepts p = 23 mod 24 so that 2 is a suitable generator.
+func generateSafePrime(bits int) (*big.Int, error) {
+	bytes := make([]byte, (bits-1+7)/8)
+	excess := uint(len(bytes)*8 - (bits - 1))
+
+	twelve := big.NewInt(12)
+	q := new(big.Int)
+	p := new(big.Int)
+	residues := make([]uint64, len(dhParamsSmallPrimes))
+
+	for {
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, err
+		}
+
+		// Set the two most significant bits so that p has exactly the requested size
+		bytes[0] &= byte(0xff >> excess)
+		bytes[0] |= byte(0xc0 >> excess)
+		q.SetBytes(bytes)
+
+		// q = 11 mod 12, which yields p = 23 mod 24
+		q.Sub(q, new(big.Int).Mod(q, twelve))
+		q.Add(q, big.NewInt(11))
+
+		for i, smallPrime := range dhParamsSmallPrimes {
+			residues[i] = new(big.Int).Mod(q, new(big.Int).SetUint64(smallPrime)).Uint64()
+		}
+
+	nextDelta:
+		for delta := uint64(0); delta < 1<<20; delta += 12 {
+			for i, smallPrime := range dhParamsSmallPrimes {
+				qResidue := (residues[i] + delta) % smallPrime
+				if qResidue == 0 || (2*qResidue+1)%smallPrime == 0 {
+					continue nextDelta
+				}
+			}
+
+			candidate := new(big.Int).Add(q, new(big.Int).SetUint64(delta))
+			p.Lsh(candidate, 1).Add(p, big.NewInt(1))
+
+			if p.BitLen() != bits {
+				break
+			}
+
+			if candidate.ProbablyPrime(0) && p.ProbablyPrime(0) && candidate.ProbablyPrime(20) && p.ProbablyPrime(20) {
+				return p, nil
+			}
+		}
+	}
+}
+
+func generateSmallPrimes(count int) []uint64 {
+	primes := make([]uint64, 0, count)
+
+	for candidate := uint64(5); len(primes) < count; candidate += 2 {
+		isPrime := true
+		for _, prime := range primes {
+			if prime*prime > candidate {
+				break
+			}
+			if candidate%prime == 0 {
+				isPrime = false
+				break
+			}
+		}
+		if isPrime && candidate%3 != 0 {
+			primes = append(primes, candidate)
+		}
+	}
+
+	return primes
+}
@@ -0,0 +1,138 @@
+package types_test
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("DHParamsGenerator", func() {
+	var generator DHParamsGenerator
+
+	parseDHParams := func(value interface{}) (*big.Int, int) {
+		block, _ := pem.Decode([]byte(value.(string)))
+		Expect(block).ToNot(BeNil())
+		Expect(block.Type).To(Equal("DH PARAMETERS"))
+
+		var params struct {
+			P *big.Int
+			G int
+		}
+		rest, err := asn1.Unmarshal(block.Bytes, &params)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rest).To(BeEmpty())
+
+		return params.P, params.G
+	}
+
+	BeforeEach(func() {
+		generator = NewDHParamsGenerator(time.Minute)
+	})
+
+	Context("Generate", func() {
+		It("generates PEM encoded parameters with a safe prime", func() {
+			params := map[interface{}]interface{}{"bits": 1024}
+			value, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			p, g := parseDHParams(value)
+			Expect(p.BitLen()).To(Equal(1024))
+			Expect(g).To(Equal(2))
+			Expect(p.ProbablyPrime(20)).To(BeTrue())
+
+			q := new(big.Int).Rsh(p, 1)
+			Expect(q.ProbablyPrime(20)).To(BeTrue())
+			Expect(new(big.Int).Mod(p, big.NewInt(24)).Int64()).To(Equal(int64(23)))
+		})
+
+		It("errors when bits is too small", func() {
+			params := map[interface{}]interface{}{"bits": 512}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate DH parameters, 'bits' must be at least 1024"))
+		})
+
+		It("errors when bits is too large", func() {
+			params := map[interface{}]interface{}{"bits": 1000000}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate DH parameters, 'bits' must be at most 8192"))
+		})
+
+		It("errors on unknown parameters", func() {
+			params := map[interface{}]interface{}{"unsupported": 32}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate DH parameters, parameters are invalid: Unsupported parameter 'unsupported'"))
+		})
+
+		Context("when generation takes longer than the timeout", func() {
+			BeforeEach(func() {
+				generator = NewDHParamsGenerator(time.Nanosecond)
+			})
+
+			It("returns a GenerationInProgressError and hands the result to a later call", func() {
+				params := map[interface{}]interface{}{"bits": 1024}
+				_, err := generator.Generate(params)
+				Expect(err).To(BeAssignableToTypeOf(GenerationInProgressError{}))
+				Expect(err.Error()).To(Equal("Generating 1024 bit DH parameters is taking longer than 1ns, retry the request to collect the result"))
+
+				var value interface{}
+				Eventually(func() error {
+					value, err = generator.Generate(params)
+					return err
+				}, 2*time.Minute, 50*time.Millisecond).Should(Succeed())
+
+				p, _ := parseDHParams(value)
+				Expect(p.BitLen()).To(Equal(1024))
+			})
+
+			It("does not hand the result to a call for another name", func() {
+				params := map[interface{}]interface{}{"bits": 1024}
+				_, err := generator.GenerateForName("first", params)
+				Expect(err).To(BeAssignableToTypeOf(GenerationInProgressError{}))
+
+				var first, second interface{}
+				Eventually(func() error {
+					second, err = generator.GenerateForName("second", params)
+					return err
+				}, 2*time.Minute, 50*time.Millisecond).Should(Succeed())
+				Eventually(func() error {
+					first, err = generator.GenerateForName("first", params)
+					return err
+				}, 2*time.Minute, 50*time.Millisecond).Should(Succeed())
+
+				firstPrime, _ := parseDHParams(first)
+				secondPrime, _ := parseDHParams(second)
+				Expect(firstPrime).ToNot(Equal(secondPrime))
+			})
+
+			It("refuses to start more generations than it can keep track of", func() {
+				params := map[interface{}]interface{}{"bits": 1024}
+				for _, name := range []string{"first", "second", "third", "fourth"} {
+					_, err := generator.GenerateForName(name, params)
+					Expect(err).To(BeAssignableToTypeOf(GenerationInProgressError{}))
+				}
+
+				_, err := generator.GenerateForName("fifth", params)
+				Expect(err).To(BeAssignableToTypeOf(GenerationInProgressError{}))
+				Expect(err.Error()).To(Equal("Too many DH parameters are being generated, retry the request later"))
+
+				Eventually(func() error {
+					_, err := generator.GenerateForName("first", params)
+					return err
+				}, 2*time.Minute, 50*time.Millisecond).Should(Succeed())
+
+				_, err = generator.GenerateForName("fifth", params)
+				Expect(err).To(BeAssignableToTypeOf(GenerationInProgressError{}))
+				Expect(err.Error()).To(HavePrefix("Generating 1024 bit DH parameters"))
+			})
+		})
+	})
+})
@@ -0,0 +1,3 @@
+package types
+
+var SHA512Crypt = sha512Crypt
@@ -0,0 +1,169 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+
+	"github.com/shono09835/config-server/config"
+)
+
+const (
+	DefaultExternalGeneratorTimeout = 30 * time.Second
+
+	// How long to wait for the output to be closed once the executable was
+	// killed, in case a child it started still holds it open
+	externalGeneratorWaitDelay = time.Second
+
+	externalGeneratorMaxOutput = 1024 * 1024
+	externalGeneratorMaxErrors = 64 * 1024
+)
+
+// ExternalGenerator delegates generation to an executable. The executable
+// reads a JSON request from stdin:
+//
+//	{"type": "<value type>", "parameters": {...}, "previous_value": <value>}
+//
+// where 'previous_value' is only present when a version is already stored,
+// and writes either {"value": <value>} or {"error": "<message>"} to stdout.
+// Exiting with a non-zero status also fails the generation.
+type ExternalGenerator struct {
+	valueType        string
+	path             string
+	args             []string
+	timeout          time.Duration
+	secretParameters []string
+}
+
+type externalGeneratorRequest struct {
+	Type          string          `json:"type"`
+	Parameters    interface{}     `json:"parameters"`
+	PreviousValue json.RawMessage `json:"previous_value,omitempty"`
+}
+
+type externalGeneratorResponse struct {
+	Value interface{} `json:"value"`
+	Error string      `json:"error"`
+}
+
+func NewExternalGenerator(valueType string, config config.ExternalGeneratorConfig) ExternalGenerator {
+	timeout := time.Duration(config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultExternalGeneratorTimeout
+	}
+
+	return ExternalGenerator{
+		valueType:        valueType,
+		path:             config.Path,
+		args:             config.Args,
+		timeout:          timeout,
+		secretParameters: config.SecretParameters,
+	}
+}
+
+// SecretParameters lists the parameters declared secret in the config.
+func (g ExternalGenerator) SecretParameters() []string {
+	return g.secretParameters
+}
+
+func (g ExternalGenerator) Generate(parameters interface{}) (interface{}, error) {
+	return g.run(externalGeneratorRequest{Type: g.valueType, Parameters: parameters})
+}
+
+// GenerateFromPrevious hands the latest stored value to the executable, so
+// that it can carry parts of it over.
+func (g ExternalGenerator) GenerateFromPrevious(parameters interface{}, previousValue string) (interface{}, error) {
+	var previous struct {
+		Value json.RawMessage `json:"value"`
+	}
+
+	err := json.Unmarshal([]byte(previousValue), &previous)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to parse previous value")
+	}
+
+	return g.run(externalGeneratorRequest{Type: g.valueType, Parameters: parameters, PreviousValue: previous.Value})
+}
+
+func (g ExternalGenerator) run(request externalGeneratorRequest) (interface{}, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to serialize external generator request")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: externalGeneratorMaxOutput}
+	stderr := &limitedBuffer{limit: externalGeneratorMaxErrors}
+
+	cmd := exec.CommandContext(ctx, g.path, g.args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = externalGeneratorWaitDelay
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.Errorf("External generator for '%s' timed out after %s", g.valueType, g.timeout)
+	}
+
+	if stdout.truncated {
+		return nil, errors.Errorf("External generator for '%s' wrote more than %d bytes", g.valueType, externalGeneratorMaxOutput)
+	}
+
+	var response externalGeneratorResponse
+	parseErr := json.Unmarshal(stdout.Bytes(), &response)
+
+	if parseErr == nil && response.Error != "" {
+		return nil, errors.Errorf("External generator for '%s' failed: %s", g.valueType, response.Error)
+	}
+
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, errors.WrapErrorf(err, "External generator for '%s' failed: %s", g.valueType, message)
+		}
+		return nil, errors.WrapErrorf(err, "External generator for '%s' failed", g.valueType)
+	}
+
+	if parseErr != nil {
+		return nil, errors.WrapErrorf(parseErr, "External generator for '%s' returned invalid JSON", g.valueType)
+	}
+
+	if response.Value == nil {
+		return nil, errors.Errorf("External generator for '%s' did not return a value", g.valueType)
+	}
+
+	return response.Value, nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest, so that the executable is never blocked writing its output.
+type limitedBuffer struct {
+	buffer    bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buffer.Len(); len(p) > remaining {
+		b.truncated = true
+		b.buffer.Write(p[:remaining]) //nolint:errcheck
+		return len(p), nil
+	}
+
+	return b.buffer.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buffer.Bytes()
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buffer.String()
+}
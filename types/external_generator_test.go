@@ -0,0 +1,162 @@
+package types_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/shono09835/config-server/config"
+	. "github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("ExternalGenerator", func() {
+	var scriptDir string
+
+	writeScript := func(body string) string {
+		path := filepath.Join(scriptDir, "generator")
+		Expect(os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0700)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		scriptDir, err = os.MkdirTemp("", "external-generator")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(scriptDir) //nolint:errcheck
+	})
+
+	Context("Generate", func() {
+		It("sends the type and parameters on stdin and returns the value", func() {
+			generator := NewExternalGenerator("echo", config.ExternalGeneratorConfig{
+				Path: writeScript(`printf '{"value":'; cat; printf '}'`),
+			})
+
+			value, err := generator.Generate(map[string]interface{}{"length": 3})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(map[string]interface{}{
+				"type":       "echo",
+				"parameters": map[string]interface{}{"length": float64(3)},
+			}))
+		})
+
+		It("passes the configured arguments", func() {
+			generator := NewExternalGenerator("args", config.ExternalGeneratorConfig{
+				Path: writeScript(`echo "{\"value\":\"$1-$2\"}"`),
+				Args: []string{"a", "b"},
+			})
+
+			value, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal("a-b"))
+		})
+
+		It("returns the error reported by the executable", func() {
+			generator := NewExternalGenerator("failing", config.ExternalGeneratorConfig{
+				Path: writeScript(`echo '{"error":"length is too short"}'; exit 1`),
+			})
+
+			_, err := generator.Generate(nil)
+			Expect(err).To(MatchError("External generator for 'failing' failed: length is too short"))
+		})
+
+		It("returns stderr when the executable exits with a non-zero status", func() {
+			generator := NewExternalGenerator("failing", config.ExternalGeneratorConfig{
+				Path: writeScript(`echo 'something broke' >&2; exit 3`),
+			})
+
+			_, err := generator.Generate(nil)
+			Expect(err).To(MatchError(ContainSubstring("External generator for 'failing' failed: something broke")))
+		})
+
+		It("returns an error for invalid output", func() {
+			generator := NewExternalGenerator("broken", config.ExternalGeneratorConfig{
+				Path: writeScript(`echo 'not json'`),
+			})
+
+			_, err := generator.Generate(nil)
+			Expect(err).To(MatchError(ContainSubstring("External generator for 'broken' returned invalid JSON")))
+		})
+
+		It("returns an error when no value is returned", func() {
+			generator := NewExternalGenerator("empty", config.ExternalGeneratorConfig{
+				Path: writeScript(`echo '{}'`),
+			})
+
+			_, err := generator.Generate(nil)
+			Expect(err).To(MatchError("External generator for 'empty' did not return a value"))
+		})
+
+		It("returns an error when the executable times out", func() {
+			generator := NewExternalGenerator("slow", config.ExternalGeneratorConfig{
+				Path:    writeScript(`exec sleep 5`),
+				Timeout: 1,
+			})
+
+			_, err := generator.Generate(nil)
+			Expect(err).To(MatchError("External generator for 'slow' timed out after 1s"))
+		})
+
+		It("returns an error when the executable leaves a child holding its output past the timeout", func() {
+			generator := NewExternalGenerator("forking", config.ExternalGeneratorConfig{
+				Path:    writeScript("sleep 30 &\nsleep 30\n"),
+				Timeout: 1,
+			})
+
+			start := time.Now()
+			_, err := generator.Generate(nil)
+			Expect(err).To(MatchError("External generator for 'forking' timed out after 1s"))
+			Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+		})
+
+		It("returns an error when the output is too large", func() {
+			generator := NewExternalGenerator("chatty", config.ExternalGeneratorConfig{
+				Path: writeScript(`head -c 2000000 /dev/zero`),
+			})
+
+			_, err := generator.Generate(nil)
+			Expect(err).To(MatchError("External generator for 'chatty' wrote more than 1048576 bytes"))
+		})
+
+		It("returns an error when the executable does not exist", func() {
+			generator := NewExternalGenerator("missing", config.ExternalGeneratorConfig{
+				Path: filepath.Join(scriptDir, "missing"),
+			})
+
+			_, err := generator.Generate(nil)
+			Expect(err).To(MatchError(ContainSubstring("External generator for 'missing' failed")))
+		})
+	})
+
+	Context("GenerateFromPrevious", func() {
+		It("sends the previous value on stdin", func() {
+			generator := NewExternalGenerator("echo", config.ExternalGeneratorConfig{
+				Path: writeScript(`printf '{"value":'; cat; printf '}'`),
+			})
+
+			value, err := generator.GenerateFromPrevious(map[string]interface{}{}, `{"value":{"token":"abc"}}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(map[string]interface{}{
+				"type":           "echo",
+				"parameters":     map[string]interface{}{},
+				"previous_value": map[string]interface{}{"token": "abc"},
+			}))
+		})
+	})
+
+	Context("SecretParameters", func() {
+		It("returns the secret parameters from the config", func() {
+			generator := NewExternalGenerator("vault_token", config.ExternalGeneratorConfig{
+				Path:             "/bin/vault-token",
+				SecretParameters: []string{"root_token"},
+			})
+
+			Expect(generator.SecretParameters()).To(Equal([]string{"root_token"}))
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// SecretParametersGenerator is implemented by generators that accept secret
+// inputs, which must never be stored alongside the generated value.
+type SecretParametersGenerator interface {
+	SecretParameters() []string
+}
+
+// NormalizeParameters renders the parameters of a generation as JSON with
+// sorted keys and without null values, so that they can be stored with the
+// generated value. Secret parameters of the generator are left out.
+func NormalizeParameters(generator ValueGenerator, parameters interface{}) (string, error) {
+	normalized := map[string]interface{}{}
+
+	if parameters != nil {
+		parametersMap, ok := parameters.(map[string]interface{})
+		if !ok {
+			return "", errors.Error("Failed to normalize parameters: expected an object")
+		}
+
+		for name, value := range WithoutSecretParameters(generator, parametersMap).(map[string]interface{}) {
+			if value == nil {
+				continue
+			}
+			normalized[name] = value
+		}
+	}
+
+	bytes, err := json.Marshal(normalized)
+	if err != nil {
+		return "", errors.WrapError(err, "Failed to normalize parameters")
+	}
+
+	return string(bytes), nil
+}
+
+// WithoutSecretParameters returns a copy of the parameters without the
+// secret parameters of the generator. Parameters that are not an object are
+// returned as they are.
+func WithoutSecretParameters(generator ValueGenerator, parameters interface{}) interface{} {
+	parametersMap, ok := parameters.(map[string]interface{})
+	if !ok {
+		return parameters
+	}
+
+	secrets := map[string]bool{}
+	if secretParametersGenerator, ok := generator.(SecretParametersGenerator); ok {
+		for _, name := range secretParametersGenerator.SecretParameters() {
+			secrets[name] = true
+		}
+	}
+
+	result := map[string]interface{}{}
+	for name, value := range parametersMap {
+		if !secrets[name] {
+			result[name] = value
+		}
+	}
+
+	return result
+}
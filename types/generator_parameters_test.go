@@ -0,0 +1,47 @@
+package types_test
+
+import (
+	"github.com/shono09835/config-server/config"
+	. "github.com/shono09835/config-server/types"
+	"github.com/shono09835/config-server/types/typesfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NormalizeParameters", func() {
+	It("sorts the keys and drops null values", func() {
+		normalized, err := NormalizeParameters(&typesfakes.FakeValueGenerator{}, map[string]interface{}{
+			"length":  30.0,
+			"charset": "abc",
+			"unused":  nil,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalized).To(Equal(`{"charset":"abc","length":30}`))
+	})
+
+	It("returns an empty object when there are no parameters", func() {
+		normalized, err := NormalizeParameters(&typesfakes.FakeValueGenerator{}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalized).To(Equal(`{}`))
+	})
+
+	It("leaves out the secret parameters of the generator", func() {
+		generator := NewExternalGenerator("vault_token", config.ExternalGeneratorConfig{
+			Path:             "/bin/vault-token",
+			SecretParameters: []string{"root_token"},
+		})
+
+		normalized, err := NormalizeParameters(generator, map[string]interface{}{
+			"policy":     "read-only",
+			"root_token": "s.secret",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(normalized).To(Equal(`{"policy":"read-only"}`))
+	})
+
+	It("returns an error when the parameters are not an object", func() {
+		_, err := NormalizeParameters(&typesfakes.FakeValueGenerator{}, "length")
+		Expect(err).To(MatchError("Failed to normalize parameters: expected an object"))
+	})
+})
@@ -0,0 +1,115 @@
+package types_test
+
+import (
+	"github.com/shono09835/config-server/config"
+	. "github.com/shono09835/config-server/types"
+	"github.com/shono09835/config-server/types/typesfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GeneratorDescriptions", func() {
+	var (
+		valueGeneratorFactory ValueGeneratorConcrete
+		descriptions          map[string]GeneratorDescription
+	)
+
+	property := func(valueType string, name string) map[string]interface{} {
+		properties := descriptions[valueType].Parameters["properties"].(map[string]interface{})
+		Expect(properties).To(HaveKey(name))
+		return properties[name].(map[string]interface{})
+	}
+
+	BeforeEach(func() {
+		valueGeneratorFactory = NewValueGeneratorConcrete(&typesfakes.FakeCertsLoader{}, &typesfakes.FakeSSHKeysLoader{}, config.GeneratorsConfig{
+			External: map[string]config.ExternalGeneratorConfig{"vault_token": {Path: "/bin/vault-token"}},
+		})
+
+		descriptions = map[string]GeneratorDescription{}
+		for _, description := range valueGeneratorFactory.GeneratorDescriptions() {
+			descriptions[description.Type] = description
+		}
+	})
+
+	It("describes every built-in type the factory supports", func() {
+		for _, valueType := range []string{"password", "ssh", "rsa", "certificate", "user", "symmetric_key", "uuid", "jwt_signing_key", "wireguard", "totp", "dh_params", "ssh_certificate"} {
+			Expect(descriptions).To(HaveKey(valueType))
+			Expect(descriptions[valueType].Example).ToNot(BeNil())
+		}
+
+		var builtInTypes []string
+		for valueType, description := range descriptions {
+			if !description.External {
+				builtInTypes = append(builtInTypes, valueType)
+			}
+		}
+		Expect(config.BuiltInValueTypes).To(ConsistOf(builtInTypes))
+
+		for valueType := range descriptions {
+			_, err := valueGeneratorFactory.GetGenerator(valueType)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("derives the parameter types from the generator parameters", func() {
+		Expect(descriptions["certificate"].Parameters["additionalProperties"]).To(Equal(false))
+
+		Expect(property("certificate", "common_name")).To(Equal(map[string]interface{}{"type": "string"}))
+		Expect(property("certificate", "is_ca")).To(Equal(map[string]interface{}{"type": "boolean"}))
+		Expect(property("certificate", "max_path_len")).To(Equal(map[string]interface{}{"type": "integer"}))
+		Expect(property("certificate", "alternative_names")).To(Equal(map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}))
+		Expect(property("ssh_certificate", "extensions")).To(Equal(map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		}))
+	})
+
+	It("includes defaults and allowed values", func() {
+		Expect(property("password", "length")["default"]).To(Equal(DefaultPasswordLength))
+		Expect(property("symmetric_key", "encoding")["enum"]).To(ContainElement("base64url"))
+		Expect(property("certificate", "key_usage")["items"]).To(HaveKeyWithValue("enum", ContainElement("cert_sign")))
+	})
+
+	It("lists required parameters", func() {
+		Expect(descriptions["totp"].Parameters["required"]).To(Equal([]string{"account"}))
+		Expect(descriptions["password"].Parameters).ToNot(HaveKey("required"))
+	})
+
+	It("describes types without parameters", func() {
+		Expect(descriptions["rsa"].Parameters["properties"]).To(BeEmpty())
+		Expect(descriptions["rsa"].Parameters).ToNot(HaveKey("additionalProperties"))
+		Expect(descriptions["ssh"].Parameters).ToNot(HaveKey("additionalProperties"))
+	})
+
+	It("describes external types", func() {
+		Expect(descriptions["vault_token"].External).To(BeTrue())
+		Expect(descriptions["vault_token"].Parameters).To(Equal(map[string]interface{}{"type": "object"}))
+	})
+
+	It("lists defaults the generators accept", func() {
+		for _, valueType := range []string{"password", "user", "symmetric_key", "uuid", "wireguard", "totp"} {
+			defaults := map[string]interface{}{}
+			for name, schema := range descriptions[valueType].Parameters["properties"].(map[string]interface{}) {
+				if defaultValue, ok := schema.(map[string]interface{})["default"]; ok {
+					defaults[name] = defaultValue
+				}
+			}
+
+			if required, ok := descriptions[valueType].Parameters["required"].([]string); ok {
+				for _, name := range required {
+					defaults[name] = "some-value"
+				}
+			}
+
+			generator, err := valueGeneratorFactory.GetGenerator(valueType)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = generator.Generate(defaults)
+			Expect(err).ToNot(HaveOccurred(), valueType)
+		}
+	})
+})
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type JWK struct {
+	KeyType   string   `json:"kty" yaml:"kty"`
+	KeyID     string   `json:"kid,omitempty" yaml:"kid,omitempty"`
+	Use       string   `json:"use,omitempty" yaml:"use,omitempty"`
+	Algorithm string   `json:"alg,omitempty" yaml:"alg,omitempty"`
+	N         string   `json:"n,omitempty" yaml:"n,omitempty"`
+	E         string   `json:"e,omitempty" yaml:"e,omitempty"`
+	Curve     string   `json:"crv,omitempty" yaml:"crv,omitempty"`
+	X         string   `json:"x,omitempty" yaml:"x,omitempty"`
+	Y         string   `json:"y,omitempty" yaml:"y,omitempty"`
+	D         string   `json:"d,omitempty" yaml:"d,omitempty"`
+	P         string   `json:"p,omitempty" yaml:"p,omitempty"`
+	Q         string   `json:"q,omitempty" yaml:"q,omitempty"`
+	DP        string   `json:"dp,omitempty" yaml:"dp,omitempty"`
+	DQ        string   `json:"dq,omitempty" yaml:"dq,omitempty"`
+	QI        string   `json:"qi,omitempty" yaml:"qi,omitempty"`
+	X5C       []string `json:"x5c,omitempty" yaml:"x5c,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys" yaml:"keys"`
+}
+
+// NewPublicJWK returns the JWK representation of an RSA or ECDSA public key.
+func NewPublicJWK(publicKey interface{}) (JWK, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType: "RSA",
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			KeyType: "EC",
+			Curve:   key.Curve.Params().Name,
+			X:       base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:       base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	default:
+		return JWK{}, errors.Errorf("Unsupported public key type %T", publicKey)
+	}
+}
+
+// NewPrivateJWK returns the JWK representation of an RSA or ECDSA private
+// key, including its public parameters.
+func NewPrivateJWK(privateKey interface{}) (JWK, error) {
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		jwk, err := NewPublicJWK(&key.PublicKey)
+		if err != nil {
+			return JWK{}, err
+		}
+
+		key.Precompute()
+
+		jwk.D = base64.RawURLEncoding.EncodeToString(key.D.Bytes())
+		jwk.P = base64.RawURLEncoding.EncodeToString(key.Primes[0].Bytes())
+		jwk.Q = base64.RawURLEncoding.EncodeToString(key.Primes[1].Bytes())
+		jwk.DP = base64.RawURLEncoding.EncodeToString(key.Precomputed.Dp.Bytes())
+		jwk.DQ = base64.RawURLEncoding.EncodeToString(key.Precomputed.Dq.Bytes())
+		jwk.QI = base64.RawURLEncoding.EncodeToString(key.Precomputed.Qinv.Bytes())
+		return jwk, nil
+	case *ecdsa.PrivateKey:
+		jwk, err := NewPublicJWK(&key.PublicKey)
+		if err != nil {
+			return JWK{}, err
+		}
+
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.D = base64.RawURLEncoding.EncodeToString(key.D.FillBytes(make([]byte, size)))
+		return jwk, nil
+	default:
+		return JWK{}, errors.Errorf("Unsupported private key type %T", privateKey)
+	}
+}
+
+// Thumbprint computes the RFC 7638 JWK thumbprint of the key.
+func (k JWK) Thumbprint() string {
+	var canonical string
+	if k.KeyType == "EC" {
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, k.Curve, k.X, k.Y)
+	} else {
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, k.E, k.N)
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,140 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const DefaultJWTSigningKeyAlgorithm = "RS256"
+
+const (
+	jwtSigningKeyDefaultRSABits   = 2048
+	jwtSigningKeyMinimumRSABits   = 2048
+	jwtSigningKeyMaximumRSABits   = 8192
+	jwtSigningKeyHeaderRSAPrivate = "RSA PRIVATE KEY"
+	jwtSigningKeyHeaderECPrivate  = "EC PRIVATE KEY"
+	jwtSigningKeyHeaderPublicKey  = "PUBLIC KEY"
+	jwtSigningKeyUse              = "sig"
+)
+
+var jwtSigningKeyCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+var jwtSigningKeyRSAAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+
+type JWTSigningKeyGenerator struct{}
+
+type JWTSigningKey struct {
+	KeyID      string `json:"kid" yaml:"kid"`
+	Algorithm  string `json:"algorithm" yaml:"algorithm"`
+	PrivateKey string `json:"private_key" yaml:"private_key"`
+	PublicKey  string `json:"public_key" yaml:"public_key"`
+	JWKS       JWKSet `json:"jwks" yaml:"jwks"`
+}
+
+type jwtSigningKeyParams struct {
+	Algorithm string `yaml:"algorithm"`
+	KeyID     string `yaml:"kid"`
+	KeyLength int    `yaml:"key_length"`
+}
+
+var supportedJWTSigningKeyParams = []string{
+	"algorithm",
+	"kid",
+	"key_length",
+}
+
+func NewJWTSigningKeyGenerator() JWTSigningKeyGenerator {
+	return JWTSigningKeyGenerator{}
+}
+
+func (g JWTSigningKeyGenerator) Generate(parameters interface{}) (interface{}, error) {
+	var params jwtSigningKeyParams
+	err := objToStruct(parameters, &params, supportedJWTSigningKeyParams)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate JWT signing key, parameters are invalid")
+	}
+
+	if params.Algorithm == "" {
+		params.Algorithm = DefaultJWTSigningKeyAlgorithm
+	}
+
+	var privateKeyPEM string
+	var publicKey interface{}
+
+	if curve, found := jwtSigningKeyCurves[params.Algorithm]; found {
+		if params.KeyLength != 0 {
+			return nil, errors.Error("Failed to generate JWT signing key, 'key_length' param is only supported for RSA algorithms")
+		}
+
+		privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, errors.WrapError(err, "Generating ECDSA key")
+		}
+
+		keyBytes, err := x509.MarshalECPrivateKey(privateKey)
+		if err != nil {
+			return nil, errors.WrapError(err, "Marshalling ECDSA key")
+		}
+
+		privateKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: jwtSigningKeyHeaderECPrivate, Bytes: keyBytes}))
+		publicKey = &privateKey.PublicKey
+	} else if stringInArray(params.Algorithm, jwtSigningKeyRSAAlgorithms) {
+		if params.KeyLength == 0 {
+			params.KeyLength = jwtSigningKeyDefaultRSABits
+		}
+
+		if params.KeyLength < jwtSigningKeyMinimumRSABits {
+			return nil, errors.Errorf("Failed to generate JWT signing key, 'key_length' must be at least %d", jwtSigningKeyMinimumRSABits)
+		}
+
+		if params.KeyLength > jwtSigningKeyMaximumRSABits {
+			return nil, errors.Errorf("Failed to generate JWT signing key, 'key_length' must be at most %d", jwtSigningKeyMaximumRSABits)
+		}
+
+		privateKey, err := rsa.GenerateKey(rand.Reader, params.KeyLength)
+		if err != nil {
+			return nil, errors.WrapError(err, "Generating RSA key")
+		}
+
+		privateKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: jwtSigningKeyHeaderRSAPrivate, Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}))
+		publicKey = &privateKey.PublicKey
+	} else {
+		return nil, errors.Errorf("Failed to generate JWT signing key, unsupported 'algorithm': %s", params.Algorithm)
+	}
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, errors.WrapError(err, "Marshalling public key")
+	}
+
+	jwk, err := NewPublicJWK(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.KeyID == "" {
+		params.KeyID = jwk.Thumbprint()
+	}
+
+	jwk.KeyID = params.KeyID
+	jwk.Use = jwtSigningKeyUse
+	jwk.Algorithm = params.Algorithm
+
+	return JWTSigningKey{
+		KeyID:      params.KeyID,
+		Algorithm:  params.Algorithm,
+		PrivateKey: privateKeyPEM,
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: jwtSigningKeyHeaderPublicKey, Bytes: publicKeyBytes})),
+		JWKS:       JWKSet{Keys: []JWK{jwk}},
+	}, nil
+}
@@ -0,0 +1,181 @@
+package types_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("JWTSigningKeyGenerator", func() {
+	var generator ValueGenerator
+
+	BeforeEach(func() {
+		generator = NewJWTSigningKeyGenerator()
+	})
+
+	Context("Generate", func() {
+		Context("when algorithm is not set", func() {
+			var key JWTSigningKey
+
+			BeforeEach(func() {
+				generated, err := generator.Generate(nil)
+				Expect(err).ToNot(HaveOccurred())
+				key = generated.(JWTSigningKey)
+			})
+
+			It("generates a 2048 bit RS256 key", func() {
+				Expect(key.Algorithm).To(Equal("RS256"))
+
+				block, _ := pem.Decode([]byte(key.PrivateKey))
+				Expect(block.Type).To(Equal("RSA PRIVATE KEY"))
+
+				privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(privateKey.N.BitLen()).To(Equal(2048))
+
+				pubBlock, _ := pem.Decode([]byte(key.PublicKey))
+				publicKey, err := x509.ParsePKIXPublicKey(pubBlock.Bytes)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(privateKey.Public()).To(Equal(publicKey))
+			})
+
+			It("returns a JWKS that verifies signatures of the private key", func() {
+				Expect(key.JWKS.Keys).To(HaveLen(1))
+
+				jwk := key.JWKS.Keys[0]
+				Expect(jwk.KeyType).To(Equal("RSA"))
+				Expect(jwk.Use).To(Equal("sig"))
+				Expect(jwk.Algorithm).To(Equal("RS256"))
+				Expect(jwk.KeyID).To(Equal(key.KeyID))
+
+				n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+				Expect(err).ToNot(HaveOccurred())
+				e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+				Expect(err).ToNot(HaveOccurred())
+				publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+				block, _ := pem.Decode([]byte(key.PrivateKey))
+				privateKey, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
+
+				digest := sha256.Sum256([]byte("payload"))
+				signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature)).To(Succeed())
+			})
+
+			It("assigns the RFC 7638 thumbprint as kid", func() {
+				jwk := key.JWKS.Keys[0]
+				thumbprint := sha256.Sum256([]byte(`{"e":"` + jwk.E + `","kty":"RSA","n":"` + jwk.N + `"}`))
+				Expect(key.KeyID).To(Equal(base64.RawURLEncoding.EncodeToString(thumbprint[:])))
+			})
+
+			It("serializes the JWKS as a JSON document", func() {
+				bytes, err := json.Marshal(key)
+				Expect(err).ToNot(HaveOccurred())
+
+				var decoded map[string]interface{}
+				Expect(json.Unmarshal(bytes, &decoded)).To(Succeed())
+
+				jwks := decoded["jwks"].(map[string]interface{})
+				keys := jwks["keys"].([]interface{})
+				Expect(keys[0]).To(HaveKeyWithValue("kty", "RSA"))
+				Expect(keys[0]).ToNot(HaveKey("crv"))
+			})
+		})
+
+		It("uses the provided kid", func() {
+			params := map[interface{}]interface{}{"kid": "key-1"}
+			generated, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			key := generated.(JWTSigningKey)
+			Expect(key.KeyID).To(Equal("key-1"))
+			Expect(key.JWKS.Keys[0].KeyID).To(Equal("key-1"))
+		})
+
+		It("generates an RSA key of custom length", func() {
+			params := map[interface{}]interface{}{"algorithm": "RS512", "key_length": 3072}
+			generated, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			block, _ := pem.Decode([]byte(generated.(JWTSigningKey).PrivateKey))
+			privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(privateKey.N.BitLen()).To(Equal(3072))
+		})
+
+		It("generates an ECDSA key for ES256", func() {
+			params := map[interface{}]interface{}{"algorithm": "ES256"}
+			generated, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			key := generated.(JWTSigningKey)
+			block, _ := pem.Decode([]byte(key.PrivateKey))
+			Expect(block.Type).To(Equal("EC PRIVATE KEY"))
+
+			privateKey, err := x509.ParseECPrivateKey(block.Bytes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(privateKey.Curve.Params().Name).To(Equal("P-256"))
+
+			jwk := key.JWKS.Keys[0]
+			Expect(jwk.KeyType).To(Equal("EC"))
+			Expect(jwk.Curve).To(Equal("P-256"))
+			Expect(jwk.Algorithm).To(Equal("ES256"))
+
+			x, _ := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, _ := base64.RawURLEncoding.DecodeString(jwk.Y)
+			Expect(x).To(HaveLen(32))
+			Expect(y).To(HaveLen(32))
+
+			publicKey := &ecdsa.PublicKey{Curve: privateKey.Curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+			Expect(publicKey.Equal(privateKey.Public())).To(BeTrue())
+		})
+
+		It("errors when key_length is set for ECDSA algorithms", func() {
+			params := map[interface{}]interface{}{"algorithm": "ES384", "key_length": 2048}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate JWT signing key, 'key_length' param is only supported for RSA algorithms"))
+		})
+
+		It("errors when key_length is too small", func() {
+			params := map[interface{}]interface{}{"key_length": 1024}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate JWT signing key, 'key_length' must be at least 2048"))
+		})
+
+		It("errors when key_length is too large", func() {
+			params := map[interface{}]interface{}{"key_length": 8193}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate JWT signing key, 'key_length' must be at most 8192"))
+		})
+
+		It("errors on unsupported algorithms", func() {
+			params := map[interface{}]interface{}{"algorithm": "HS256"}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate JWT signing key, unsupported 'algorithm': HS256"))
+		})
+
+		It("errors on unknown parameters", func() {
+			params := map[interface{}]interface{}{"unsupported": 32}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate JWT signing key, parameters are invalid: Unsupported parameter 'unsupported'"))
+		})
+	})
+})
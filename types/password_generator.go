@@ -40,17 +40,26 @@ func (passwordGenerator) Generate(parameters interface{}) (interface{}, error) {
 		params.Length = DefaultPasswordLength
 	}
 
+	password, err := generateRandomString(params.Length)
+	if err != nil {
+		return nil, err
+	}
+
+	return password, nil
+}
+
+func generateRandomString(length int) (string, error) {
 	lengthLetterRunes := big.NewInt(int64(len(letterRunes)))
-	passwordRunes := make([]rune, params.Length)
+	runes := make([]rune, length)
 
-	for i := range passwordRunes {
+	for i := range runes {
 		index, err := rand.Int(rand.Reader, lengthLetterRunes)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
-		passwordRunes[i] = letterRunes[index.Int64()]
+		runes[i] = letterRunes[index.Int64()]
 	}
 
-	return string(passwordRunes), nil
+	return string(runes), nil
 }
@@ -0,0 +1,186 @@
+package types
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	PasswordHashBcrypt       = "bcrypt"
+	PasswordHashSHA512Crypt  = "sha512-crypt"
+	PasswordHashPBKDF2SHA256 = "pbkdf2-sha256"
+
+	bcryptMaxPasswordLength = 72
+
+	sha512CryptRounds     = 5000
+	sha512CryptSaltLength = 16
+
+	pbkdf2Iterations = 100000
+	pbkdf2SaltLength = 16
+	pbkdf2KeyLength  = 32
+)
+
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var supportedPasswordHashAlgorithms = []string{
+	PasswordHashBcrypt,
+	PasswordHashSHA512Crypt,
+	PasswordHashPBKDF2SHA256,
+}
+
+func hashPassword(password string, algorithm string) (string, error) {
+	switch algorithm {
+	case PasswordHashBcrypt:
+		if len(password) > bcryptMaxPasswordLength {
+			return "", errors.Errorf("Passwords longer than %d characters cannot be hashed with bcrypt", bcryptMaxPasswordLength)
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", errors.WrapError(err, "Generating bcrypt hash")
+		}
+
+		return string(hash), nil
+	case PasswordHashSHA512Crypt:
+		salt, err := generateCryptSalt(sha512CryptSaltLength)
+		if err != nil {
+			return "", err
+		}
+
+		return sha512Crypt(password, salt), nil
+	case PasswordHashPBKDF2SHA256:
+		salt := make([]byte, pbkdf2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", errors.WrapError(err, "Generating salt")
+		}
+
+		key := pbkdf2.Key([]byte(password), salt, pbkdf2Iterations, pbkdf2KeyLength, sha256.New)
+
+		return fmt.Sprintf("$pbkdf2-sha256$%d$%s$%s", pbkdf2Iterations, adaptedBase64(salt), adaptedBase64(key)), nil
+	default:
+		return "", errors.Errorf("Unsupported password hash algorithm: %s", algorithm)
+	}
+}
+
+// adaptedBase64 is the unpadded base64 variant used by passlib's modular
+// crypt formats, with '.' in place of '+'.
+func adaptedBase64(data []byte) string {
+	return strings.ReplaceAll(base64.RawStdEncoding.EncodeToString(data), "+", ".")
+}
+
+func generateCryptSalt(length int) (string, error) {
+	salt := make([]byte, length)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.WrapError(err, "Generating salt")
+	}
+
+	for i := range salt {
+		salt[i] = cryptAlphabet[int(salt[i])%len(cryptAlphabet)]
+	}
+
+	return string(salt), nil
+}
+
+// sha512Crypt implements the SHA-512 based crypt(3) scheme ("$6$") as
+// specified by Ulrich Drepper, using the default number of rounds.
+func sha512Crypt(password, salt string) string {
+	p := []byte(password)
+	s := []byte(salt)
+
+	b := sha512.New()
+	b.Write(p)
+	b.Write(s)
+	b.Write(p)
+	digestB := b.Sum(nil)
+
+	a := sha512.New()
+	a.Write(p)
+	a.Write(s)
+	a.Write(repeatBytes(digestB, len(p)))
+	for i := len(p); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			a.Write(digestB)
+		} else {
+			a.Write(p)
+		}
+	}
+	digestA := a.Sum(nil)
+
+	dp := sha512.New()
+	for i := 0; i < len(p); i++ {
+		dp.Write(p)
+	}
+	pBytes := repeatBytes(dp.Sum(nil), len(p))
+
+	ds := sha512.New()
+	for i := 0; i < 16+int(digestA[0]); i++ {
+		ds.Write(s)
+	}
+	sBytes := repeatBytes(ds.Sum(nil), len(s))
+
+	digest := digestA
+	for i := 0; i < sha512CryptRounds; i++ {
+		c := sha512.New()
+		if i%2 != 0 {
+			c.Write(pBytes)
+		} else {
+			c.Write(digest)
+		}
+		if i%3 != 0 {
+			c.Write(sBytes)
+		}
+		if i%7 != 0 {
+			c.Write(pBytes)
+		}
+		if i%2 != 0 {
+			c.Write(digest)
+		} else {
+			c.Write(pBytes)
+		}
+		digest = c.Sum(nil)
+	}
+
+	permutation := [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41},
+	}
+
+	var encoded strings.Builder
+	for _, group := range permutation {
+		encodeCrypt24(&encoded, digest[group[0]], digest[group[1]], digest[group[2]], 4)
+	}
+	encodeCrypt24(&encoded, 0, 0, digest[63], 2)
+
+	return fmt.Sprintf("$6$%s$%s", salt, encoded.String())
+}
+
+func repeatBytes(source []byte, length int) []byte {
+	result := make([]byte, 0, length)
+	for len(result) < length {
+		remaining := length - len(result)
+		if remaining > len(source) {
+			remaining = len(source)
+		}
+		result = append(result, source[:remaining]...)
+	}
+	return result
+}
+
+func encodeCrypt24(out *strings.Builder, b2, b1, b0 byte, n int) {
+	w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+	for i := 0; i < n; i++ {
+		out.WriteByte(cryptAlphabet[w&0x3f])
+		w >>= 6
+	}
+}
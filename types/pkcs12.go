@@ -0,0 +1,313 @@
+package types
+
+import (
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"hash"
+	"math/big"
+	"unicode/utf16"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	pkcs12Iterations = 2048
+	pkcs12SaltLength = 8
+)
+
+var (
+	oidDataContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidCertBag                  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidPKCS8ShroudedKeyBag      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertTypeX509Certificate  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidLocalKeyID               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	oidPBEWithSHAAnd3KeyTripleD = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidSHA1                     = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+)
+
+type pfxPdu struct {
+	Version  int
+	AuthSafe pkcs12ContentInfo
+	MacData  pkcs12MacData
+}
+
+type pkcs12ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type pkcs12MacData struct {
+	Mac        pkcs12DigestInfo
+	MacSalt    []byte
+	Iterations int
+}
+
+type pkcs12DigestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type pkcs12SafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type pkcs12CertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type pkcs12EncryptedPrivateKeyInfo struct {
+	AlgorithmIdentifier pkix.AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+type pkcs12PBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// EncodePKCS12 builds a password protected PKCS#12 bundle (RFC 7292) holding
+// the private key, its certificate and the CA certificates. Keys are
+// encrypted with pbeWithSHAAnd3-KeyTripleDES-CBC and the bundle is
+// authenticated with HMAC-SHA1, which every Java and .NET version can read.
+func EncodePKCS12(privateKey interface{}, certificate *x509.Certificate, caCerts []*x509.Certificate, password string) ([]byte, error) {
+	bmpPassword := bmpString(password)
+
+	localKeyID := sha1.Sum(certificate.Raw) //nolint:gosec
+	localKeyIDAttribute, err := newPKCS12Attribute(oidLocalKeyID, localKeyID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	var certBags []pkcs12SafeBag
+	for i, cert := range append([]*x509.Certificate{certificate}, caCerts...) {
+		certBag, err := newPKCS12SafeBag(oidCertBag, pkcs12CertBag{ID: oidCertTypeX509Certificate, Data: cert.Raw})
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			certBag.Attributes = []pkcs12Attribute{localKeyIDAttribute}
+		}
+
+		certBags = append(certBags, certBag)
+	}
+
+	encryptedKey, err := encryptPKCS12PrivateKey(privateKey, bmpPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBag, err := newPKCS12SafeBag(oidPKCS8ShroudedKeyBag, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	keyBag.Attributes = []pkcs12Attribute{localKeyIDAttribute}
+
+	var authenticatedSafe []pkcs12ContentInfo
+	for _, bags := range [][]pkcs12SafeBag{certBags, {keyBag}} {
+		safeContents, err := asn1.Marshal(bags)
+		if err != nil {
+			return nil, errors.WrapError(err, "Encoding PKCS#12 safe contents")
+		}
+
+		contentInfo, err := newPKCS12DataContentInfo(safeContents)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticatedSafe = append(authenticatedSafe, contentInfo)
+	}
+
+	authenticatedSafeBytes, err := asn1.Marshal(authenticatedSafe)
+	if err != nil {
+		return nil, errors.WrapError(err, "Encoding PKCS#12 authenticated safe")
+	}
+
+	macSalt := make([]byte, pkcs12SaltLength)
+	if _, err := rand.Read(macSalt); err != nil {
+		return nil, errors.WrapError(err, "Generating salt")
+	}
+
+	macKey := pkcs12KDF(sha1.New, 64, bmpPassword, macSalt, 3, pkcs12Iterations, 20)
+	mac := hmac.New(sha1.New, macKey)
+	mac.Write(authenticatedSafeBytes)
+
+	authSafe, err := newPKCS12DataContentInfo(authenticatedSafeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	pfx, err := asn1.Marshal(pfxPdu{
+		Version:  3,
+		AuthSafe: authSafe,
+		MacData: pkcs12MacData{
+			Mac: pkcs12DigestInfo{
+				Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA1, Parameters: asn1.NullRawValue},
+				Digest:    mac.Sum(nil),
+			},
+			MacSalt:    macSalt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+	if err != nil {
+		return nil, errors.WrapError(err, "Encoding PKCS#12")
+	}
+
+	return pfx, nil
+}
+
+func encryptPKCS12PrivateKey(privateKey interface{}, bmpPassword []byte) (pkcs12EncryptedPrivateKeyInfo, error) {
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return pkcs12EncryptedPrivateKeyInfo{}, errors.WrapError(err, "Encoding PKCS#8 private key")
+	}
+
+	salt := make([]byte, pkcs12SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return pkcs12EncryptedPrivateKeyInfo{}, errors.WrapError(err, "Generating salt")
+	}
+
+	key := pkcs12KDF(sha1.New, 64, bmpPassword, salt, 1, pkcs12Iterations, 24)
+	iv := pkcs12KDF(sha1.New, 64, bmpPassword, salt, 2, pkcs12Iterations, des.BlockSize)
+
+	block, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return pkcs12EncryptedPrivateKeyInfo{}, errors.WrapError(err, "Creating cipher")
+	}
+
+	padding := des.BlockSize - len(pkcs8Key)%des.BlockSize
+	encrypted := make([]byte, len(pkcs8Key)+padding)
+	copy(encrypted, pkcs8Key)
+	for i := len(pkcs8Key); i < len(encrypted); i++ {
+		encrypted[i] = byte(padding)
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+
+	params, err := asn1.Marshal(pkcs12PBEParams{Salt: salt, Iterations: pkcs12Iterations})
+	if err != nil {
+		return pkcs12EncryptedPrivateKeyInfo{}, errors.WrapError(err, "Encoding PBE parameters")
+	}
+
+	return pkcs12EncryptedPrivateKeyInfo{
+		AlgorithmIdentifier: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBEWithSHAAnd3KeyTripleD,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		EncryptedData: encrypted,
+	}, nil
+}
+
+func newPKCS12SafeBag(id asn1.ObjectIdentifier, value interface{}) (pkcs12SafeBag, error) {
+	bytes, err := asn1.Marshal(value)
+	if err != nil {
+		return pkcs12SafeBag{}, errors.WrapError(err, "Encoding PKCS#12 bag")
+	}
+
+	return pkcs12SafeBag{
+		ID:    id,
+		Value: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: bytes},
+	}, nil
+}
+
+func newPKCS12Attribute(id asn1.ObjectIdentifier, value []byte) (pkcs12Attribute, error) {
+	bytes, err := asn1.Marshal(value)
+	if err != nil {
+		return pkcs12Attribute{}, errors.WrapError(err, "Encoding PKCS#12 attribute")
+	}
+
+	return pkcs12Attribute{
+		ID:    id,
+		Value: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: bytes},
+	}, nil
+}
+
+func newPKCS12DataContentInfo(data []byte) (pkcs12ContentInfo, error) {
+	bytes, err := asn1.Marshal(data)
+	if err != nil {
+		return pkcs12ContentInfo{}, errors.WrapError(err, "Encoding PKCS#12 content")
+	}
+
+	return pkcs12ContentInfo{
+		ContentType: oidDataContentType,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: bytes},
+	}, nil
+}
+
+// bmpString encodes a password as the null terminated UTF-16BE string used
+// by the PKCS#12 key derivation.
+func bmpString(s string) []byte {
+	encoded := utf16.Encode([]rune(s))
+
+	result := make([]byte, 0, 2*len(encoded)+2)
+	for _, r := range encoded {
+		result = append(result, byte(r>>8), byte(r))
+	}
+
+	return append(result, 0, 0)
+}
+
+// pkcs12KDF implements the PKCS#12 key derivation function (RFC 7292,
+// Appendix B.2) for a hash with block size v.
+func pkcs12KDF(newHash func() hash.Hash, v int, password, salt []byte, id byte, iterations, size int) []byte {
+	diversifier := make([]byte, v)
+	for i := range diversifier {
+		diversifier[i] = id
+	}
+
+	fill := func(source []byte) []byte {
+		if len(source) == 0 {
+			return nil
+		}
+		return repeatBytes(source, v*((len(source)+v-1)/v))
+	}
+
+	input := append(fill(salt), fill(password)...)
+	one := big.NewInt(1)
+
+	var result []byte
+	for len(result) < size {
+		h := newHash()
+		h.Write(diversifier)
+		h.Write(input)
+		a := h.Sum(nil)
+
+		for i := 1; i < iterations; i++ {
+			h = newHash()
+			h.Write(a)
+			a = h.Sum(nil)
+		}
+
+		result = append(result, a...)
+
+		b := new(big.Int).SetBytes(repeatBytes(a, v))
+		b.Add(b, one)
+
+		for j := 0; j < len(input); j += v {
+			block := new(big.Int).SetBytes(input[j : j+v])
+			block.Add(block, b)
+
+			blockBytes := block.Bytes()
+			if len(blockBytes) > v {
+				blockBytes = blockBytes[len(blockBytes)-v:]
+			}
+			copy(input[j:j+v], make([]byte, v-len(blockBytes)))
+			copy(input[j+v-len(blockBytes):j+v], blockBytes)
+		}
+	}
+
+	return result[:size]
+}
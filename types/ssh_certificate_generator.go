@@ -0,0 +1,191 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const DefaultSSHCertificateDuration = 365
+
+var defaultSSHUserCertificateExtensions = map[string]string{
+	"permit-X11-forwarding":   "",
+	"permit-agent-forwarding": "",
+	"permit-port-forwarding":  "",
+	"permit-pty":              "",
+	"permit-user-rc":          "",
+}
+
+type SSHCertificateGenerator struct {
+	loader SSHKeysLoader
+}
+
+type SSHCertificate struct {
+	Certificate string `json:"certificate" yaml:"certificate"`
+	PrivateKey  string `json:"private_key,omitempty" yaml:"private_key,omitempty"`
+	PublicKey   string `json:"public_key" yaml:"public_key"`
+	CA          string `json:"ca" yaml:"ca"`
+}
+
+type sshCertificateParams struct {
+	CAName          string            `yaml:"ca"`
+	CertType        string            `yaml:"cert_type"`
+	KeyID           string            `yaml:"key_id"`
+	Principals      []string          `yaml:"principals"`
+	PublicKey       string            `yaml:"public_key"`
+	ValidAfter      string            `yaml:"valid_after"`
+	ValidBefore     string            `yaml:"valid_before"`
+	Duration        int64             `yaml:"duration"`
+	CriticalOptions map[string]string `yaml:"critical_options"`
+	Extensions      map[string]string `yaml:"extensions"`
+}
+
+var supportedSSHCertificateParams = []string{
+	"ca",
+	"cert_type",
+	"key_id",
+	"principals",
+	"public_key",
+	"valid_after",
+	"valid_before",
+	"duration",
+	"critical_options",
+	"extensions",
+}
+
+func NewSSHCertificateGenerator(loader SSHKeysLoader) SSHCertificateGenerator {
+	return SSHCertificateGenerator{loader: loader}
+}
+
+func (g SSHCertificateGenerator) Generate(parameters interface{}) (interface{}, error) {
+	var params sshCertificateParams
+	err := objToStruct(parameters, &params, supportedSSHCertificateParams)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate SSH certificate, parameters are invalid")
+	}
+
+	if params.CAName == "" {
+		return nil, errors.Error("Missing required CA name")
+	}
+
+	certificate := &ssh.Certificate{
+		KeyId:           params.KeyID,
+		ValidPrincipals: params.Principals,
+		Permissions: ssh.Permissions{
+			CriticalOptions: params.CriticalOptions,
+			Extensions:      params.Extensions,
+		},
+	}
+
+	switch params.CertType {
+	case "", "user":
+		certificate.CertType = ssh.UserCert
+		if certificate.Extensions == nil {
+			certificate.Extensions = defaultSSHUserCertificateExtensions
+		}
+	case "host":
+		certificate.CertType = ssh.HostCert
+		if len(params.CriticalOptions) != 0 || len(params.Extensions) != 0 {
+			return nil, errors.Error("Failed to generate SSH certificate, host certificates do not support 'critical_options' or 'extensions'")
+		}
+	default:
+		return nil, errors.Errorf("Failed to generate SSH certificate, unsupported 'cert_type': %s", params.CertType)
+	}
+
+	certificate.ValidAfter, certificate.ValidBefore, err = g.validity(params)
+	if err != nil {
+		return nil, err
+	}
+
+	serial := make([]byte, 8)
+	if _, err := rand.Read(serial); err != nil {
+		return nil, errors.WrapError(err, "Generating serial")
+	}
+	certificate.Serial = binary.BigEndian.Uint64(serial)
+
+	var result SSHCertificate
+
+	if params.PublicKey != "" {
+		certificate.Key, _, _, _, err = ssh.ParseAuthorizedKey([]byte(params.PublicKey))
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to generate SSH certificate, 'public_key' is invalid")
+		}
+		result.PublicKey = string(ssh.MarshalAuthorizedKey(certificate.Key))
+	} else {
+		generated, err := NewSSHKeyGenerator().Generate(nil)
+		if err != nil {
+			return nil, err
+		}
+		sshKey := generated.(SSHKey)
+
+		certificate.Key, _, _, _, err = ssh.ParseAuthorizedKey([]byte(sshKey.PublicKey))
+		if err != nil {
+			return nil, errors.WrapError(err, "Parsing generated public key")
+		}
+		result.PrivateKey = sshKey.PrivateKey
+		result.PublicKey = sshKey.PublicKey
+	}
+
+	if g.loader == nil {
+		panic("Expected SSHCertificateGenerator to have Loader set")
+	}
+
+	caSigner, err := g.loader.LoadSSHKey(params.CAName)
+	if err != nil {
+		return nil, errors.WrapError(err, "Loading SSH CA key")
+	}
+
+	err = certificate.SignCert(rand.Reader, caSigner)
+	if err != nil {
+		return nil, errors.WrapError(err, "Signing SSH certificate")
+	}
+
+	result.Certificate = string(ssh.MarshalAuthorizedKey(certificate))
+	result.CA = string(ssh.MarshalAuthorizedKey(caSigner.PublicKey()))
+
+	return result, nil
+}
+
+func (g SSHCertificateGenerator) validity(params sshCertificateParams) (uint64, uint64, error) {
+	validAfter := time.Now()
+
+	if params.ValidAfter != "" {
+		parsed, err := time.Parse(time.RFC3339, params.ValidAfter)
+		if err != nil {
+			return 0, 0, errors.WrapError(err, "Failed to generate SSH certificate, 'valid_after' must be an RFC 3339 timestamp")
+		}
+		validAfter = parsed
+	}
+
+	var validBefore time.Time
+
+	if params.ValidBefore != "" {
+		if params.Duration != 0 {
+			return 0, 0, errors.Error("Failed to generate SSH certificate, only one of 'valid_before' and 'duration' can be set")
+		}
+
+		parsed, err := time.Parse(time.RFC3339, params.ValidBefore)
+		if err != nil {
+			return 0, 0, errors.WrapError(err, "Failed to generate SSH certificate, 'valid_before' must be an RFC 3339 timestamp")
+		}
+		validBefore = parsed
+	} else {
+		if params.Duration < 0 {
+			return 0, 0, errors.Error("Failed to generate SSH certificate, 'duration' param cannot be negative")
+		}
+
+		if params.Duration == 0 {
+			params.Duration = DefaultSSHCertificateDuration
+		}
+		validBefore = validAfter.Add(time.Duration(params.Duration*24) * time.Hour)
+	}
+
+	if !validBefore.After(validAfter) {
+		return 0, 0, errors.Error("Failed to generate SSH certificate, 'valid_before' must be after 'valid_after'")
+	}
+
+	return uint64(validAfter.Unix()), uint64(validBefore.Unix()), nil
+}
@@ -0,0 +1,227 @@
+package types_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/shono09835/config-server/types"
+	"github.com/shono09835/config-server/types/typesfakes"
+)
+
+var _ = Describe("SSHCertificateGenerator", func() {
+	var (
+		generator ValueGenerator
+		loader    *typesfakes.FakeSSHKeysLoader
+		caSigner  ssh.Signer
+	)
+
+	parseCertificate := func(value interface{}) *ssh.Certificate {
+		pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(value.(SSHCertificate).Certificate))
+		Expect(err).ToNot(HaveOccurred())
+
+		cert, ok := pubKey.(*ssh.Certificate)
+		Expect(ok).To(BeTrue())
+		return cert
+	}
+
+	BeforeEach(func() {
+		caKey, err := NewSSHKeyGenerator().Generate(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		caSigner, err = ssh.ParsePrivateKey([]byte(caKey.(SSHKey).PrivateKey))
+		Expect(err).ToNot(HaveOccurred())
+
+		loader = &typesfakes.FakeSSHKeysLoader{}
+		loader.LoadSSHKeyReturns(caSigner, nil)
+
+		generator = NewSSHCertificateGenerator(loader)
+	})
+
+	Context("Generate", func() {
+		It("generates a user certificate signed by the CA for a new key pair", func() {
+			value, err := generator.Generate(map[interface{}]interface{}{
+				"ca":         "my-ssh-ca",
+				"key_id":     "alice",
+				"principals": []interface{}{"alice", "admin"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loader.LoadSSHKeyArgsForCall(0)).To(Equal("my-ssh-ca"))
+
+			sshCert := value.(SSHCertificate)
+			Expect(sshCert.PrivateKey).To(ContainSubstring("PRIVATE KEY"))
+			Expect(sshCert.CA).To(Equal(string(ssh.MarshalAuthorizedKey(caSigner.PublicKey()))))
+
+			cert := parseCertificate(value)
+			Expect(cert.CertType).To(Equal(uint32(ssh.UserCert)))
+			Expect(cert.KeyId).To(Equal("alice"))
+			Expect(cert.ValidPrincipals).To(Equal([]string{"alice", "admin"}))
+			Expect(cert.Extensions).To(HaveKey("permit-pty"))
+			Expect(string(ssh.MarshalAuthorizedKey(cert.Key))).To(Equal(sshCert.PublicKey))
+
+			checker := ssh.CertChecker{
+				IsUserAuthority: func(auth ssh.PublicKey) bool {
+					return string(auth.Marshal()) == string(caSigner.PublicKey().Marshal())
+				},
+			}
+			_, err = checker.Authenticate(connMetadata("alice"), cert)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("signs a provided public key without returning a private key", func() {
+			userKey, err := NewSSHKeyGenerator().Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			value, err := generator.Generate(map[interface{}]interface{}{
+				"ca":         "my-ssh-ca",
+				"public_key": userKey.(SSHKey).PublicKey,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			sshCert := value.(SSHCertificate)
+			Expect(sshCert.PrivateKey).To(BeEmpty())
+			Expect(sshCert.PublicKey).To(Equal(userKey.(SSHKey).PublicKey))
+		})
+
+		It("generates host certificates without extensions", func() {
+			value, err := generator.Generate(map[interface{}]interface{}{
+				"ca":         "my-ssh-ca",
+				"cert_type":  "host",
+				"principals": []interface{}{"host.example.com"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			cert := parseCertificate(value)
+			Expect(cert.CertType).To(Equal(uint32(ssh.HostCert)))
+			Expect(cert.Extensions).To(BeEmpty())
+		})
+
+		It("uses the provided critical options and extensions", func() {
+			value, err := generator.Generate(map[interface{}]interface{}{
+				"ca":               "my-ssh-ca",
+				"critical_options": map[interface{}]interface{}{"force-command": "/bin/true"},
+				"extensions":       map[interface{}]interface{}{"permit-pty": ""},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			cert := parseCertificate(value)
+			Expect(cert.CriticalOptions).To(Equal(map[string]string{"force-command": "/bin/true"}))
+			Expect(cert.Extensions).To(Equal(map[string]string{"permit-pty": ""}))
+		})
+
+		It("is valid for 365 days by default", func() {
+			value, err := generator.Generate(map[interface{}]interface{}{"ca": "my-ssh-ca"})
+			Expect(err).ToNot(HaveOccurred())
+
+			cert := parseCertificate(value)
+			Expect(cert.ValidBefore - cert.ValidAfter).To(Equal(uint64(365 * 24 * 60 * 60)))
+		})
+
+		It("uses the provided validity window", func() {
+			value, err := generator.Generate(map[interface{}]interface{}{
+				"ca":           "my-ssh-ca",
+				"valid_after":  "2030-01-01T00:00:00Z",
+				"valid_before": "2030-01-02T00:00:00Z",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			cert := parseCertificate(value)
+			Expect(cert.ValidAfter).To(Equal(uint64(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Unix())))
+			Expect(cert.ValidBefore).To(Equal(uint64(time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC).Unix())))
+		})
+
+		It("errors when the CA name is missing", func() {
+			_, err := generator.Generate(nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Missing required CA name"))
+		})
+
+		It("errors when the CA cannot be loaded", func() {
+			loader.LoadSSHKeyReturns(nil, errors.New("No SSH key found"))
+
+			_, err := generator.Generate(map[interface{}]interface{}{"ca": "my-ssh-ca"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Loading SSH CA key: No SSH key found"))
+		})
+
+		It("errors on unsupported certificate types", func() {
+			_, err := generator.Generate(map[interface{}]interface{}{"ca": "my-ssh-ca", "cert_type": "server"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate SSH certificate, unsupported 'cert_type': server"))
+		})
+
+		It("errors when host certificates are given extensions", func() {
+			_, err := generator.Generate(map[interface{}]interface{}{
+				"ca":         "my-ssh-ca",
+				"cert_type":  "host",
+				"extensions": map[interface{}]interface{}{"permit-pty": ""},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate SSH certificate, host certificates do not support 'critical_options' or 'extensions'"))
+		})
+
+		It("errors when both valid_before and duration are set", func() {
+			_, err := generator.Generate(map[interface{}]interface{}{
+				"ca":           "my-ssh-ca",
+				"valid_before": "2030-01-02T00:00:00Z",
+				"duration":     10,
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate SSH certificate, only one of 'valid_before' and 'duration' can be set"))
+		})
+
+		It("errors when valid_before is not after valid_after", func() {
+			_, err := generator.Generate(map[interface{}]interface{}{
+				"ca":           "my-ssh-ca",
+				"valid_after":  "2030-01-02T00:00:00Z",
+				"valid_before": "2030-01-01T00:00:00Z",
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate SSH certificate, 'valid_before' must be after 'valid_after'"))
+		})
+
+		It("errors on an invalid public key", func() {
+			_, err := generator.Generate(map[interface{}]interface{}{"ca": "my-ssh-ca", "public_key": "garbage"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("Failed to generate SSH certificate, 'public_key' is invalid"))
+		})
+
+		It("errors on unknown parameters", func() {
+			_, err := generator.Generate(map[interface{}]interface{}{"ca": "my-ssh-ca", "unsupported": 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate SSH certificate, parameters are invalid: Unsupported parameter 'unsupported'"))
+		})
+
+		It("serializes nicely in json/yaml", func() {
+			value, err := generator.Generate(map[interface{}]interface{}{"ca": "my-ssh-ca"})
+			Expect(err).ToNot(HaveOccurred())
+
+			bytes, err := yaml.Marshal(value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(bytes)).To(ContainSubstring("certificate"))
+
+			bytes, err = json.Marshal(value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(bytes)).To(ContainSubstring("certificate"))
+		})
+	})
+})
+
+type fakeConnMetadata struct {
+	user string
+}
+
+func connMetadata(user string) ssh.ConnMetadata { return fakeConnMetadata{user: user} }
+
+func (m fakeConnMetadata) User() string          { return m.user }
+func (m fakeConnMetadata) SessionID() []byte     { return nil }
+func (m fakeConnMetadata) ClientVersion() []byte { return nil }
+func (m fakeConnMetadata) ServerVersion() []byte { return nil }
+func (m fakeConnMetadata) RemoteAddr() net.Addr  { return nil }
+func (m fakeConnMetadata) LocalAddr() net.Addr   { return nil }
@@ -0,0 +1,11 @@
+package types
+
+import (
+	"golang.org/x/crypto/ssh"
+)
+
+//go:generate counterfeiter . SSHKeysLoader
+
+type SSHKeysLoader interface {
+	LoadSSHKey(string) (ssh.Signer, error)
+}
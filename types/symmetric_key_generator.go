@@ -0,0 +1,79 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	DefaultSymmetricKeyLengthBytes = 32
+	MaxSymmetricKeyLengthBytes     = 1024
+)
+
+const (
+	SymmetricKeyEncodingHex        = "hex"
+	SymmetricKeyEncodingBase64     = "base64"
+	SymmetricKeyEncodingBase64URL  = "base64url"
+	SymmetricKeyEncodingRawURLSafe = "raw-url-safe"
+)
+
+type SymmetricKeyGenerator struct{}
+
+type symmetricKeyParams struct {
+	LengthBytes int    `yaml:"length_bytes"`
+	Encoding    string `yaml:"encoding"`
+}
+
+var supportedSymmetricKeyParams = []string{
+	"length_bytes",
+	"encoding",
+}
+
+func NewSymmetricKeyGenerator() SymmetricKeyGenerator {
+	return SymmetricKeyGenerator{}
+}
+
+func (g SymmetricKeyGenerator) Generate(parameters interface{}) (interface{}, error) {
+	var params symmetricKeyParams
+	err := objToStruct(parameters, &params, supportedSymmetricKeyParams)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate symmetric key, parameters are invalid")
+	}
+
+	if params.LengthBytes < 0 {
+		return nil, errors.Error("Failed to generate symmetric key, 'length_bytes' param cannot be negative")
+	}
+
+	if params.LengthBytes > MaxSymmetricKeyLengthBytes {
+		return nil, errors.Errorf("Failed to generate symmetric key, 'length_bytes' param cannot be greater than %d", MaxSymmetricKeyLengthBytes)
+	}
+
+	if params.LengthBytes == 0 {
+		params.LengthBytes = DefaultSymmetricKeyLengthBytes
+	}
+
+	if params.Encoding == "" {
+		params.Encoding = SymmetricKeyEncodingHex
+	}
+
+	key := make([]byte, params.LengthBytes)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.WrapError(err, "Generating symmetric key")
+	}
+
+	switch params.Encoding {
+	case SymmetricKeyEncodingHex:
+		return hex.EncodeToString(key), nil
+	case SymmetricKeyEncodingBase64:
+		return base64.StdEncoding.EncodeToString(key), nil
+	case SymmetricKeyEncodingBase64URL:
+		return base64.URLEncoding.EncodeToString(key), nil
+	case SymmetricKeyEncodingRawURLSafe:
+		return base64.RawURLEncoding.EncodeToString(key), nil
+	default:
+		return nil, errors.Errorf("Failed to generate symmetric key, unsupported 'encoding': %s", params.Encoding)
+	}
+}
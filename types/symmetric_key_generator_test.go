@@ -0,0 +1,110 @@
+package types_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("SymmetricKeyGenerator", func() {
+	var generator ValueGenerator
+
+	BeforeEach(func() {
+		generator = NewSymmetricKeyGenerator()
+	})
+
+	Context("Generate", func() {
+		It("generates a hex encoded 32 byte key by default", func() {
+			key, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			decoded, err := hex.DecodeString(key.(string))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(HaveLen(32))
+		})
+
+		It("generates a key of custom length", func() {
+			params := map[interface{}]interface{}{"length_bytes": 64}
+			key, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			decoded, err := hex.DecodeString(key.(string))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(HaveLen(64))
+		})
+
+		It("encodes the key as base64", func() {
+			params := map[interface{}]interface{}{"length_bytes": 16, "encoding": "base64"}
+			key, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			decoded, err := base64.StdEncoding.DecodeString(key.(string))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(HaveLen(16))
+		})
+
+		It("encodes the key as base64url", func() {
+			params := map[interface{}]interface{}{"length_bytes": 16, "encoding": "base64url"}
+			key, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key).To(HaveSuffix("=="))
+
+			decoded, err := base64.URLEncoding.DecodeString(key.(string))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(HaveLen(16))
+		})
+
+		It("encodes the key as unpadded url-safe base64", func() {
+			params := map[interface{}]interface{}{"length_bytes": 16, "encoding": "raw-url-safe"}
+			key, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key).To(MatchRegexp("^[A-Za-z0-9_-]{22}$"))
+
+			decoded, err := base64.RawURLEncoding.DecodeString(key.(string))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(HaveLen(16))
+		})
+
+		It("generates unique keys", func() {
+			key1, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			key2, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(key1).ToNot(Equal(key2))
+		})
+
+		It("errors on unsupported encodings", func() {
+			params := map[interface{}]interface{}{"encoding": "base32"}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate symmetric key, unsupported 'encoding': base32"))
+		})
+
+		It("errors on negative number for length_bytes", func() {
+			params := map[interface{}]interface{}{"length_bytes": -1}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate symmetric key, 'length_bytes' param cannot be negative"))
+		})
+
+		It("errors when length_bytes is greater than the maximum", func() {
+			params := map[interface{}]interface{}{"length_bytes": 1025}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate symmetric key, 'length_bytes' param cannot be greater than 1024"))
+		})
+
+		It("errors on unknown parameters", func() {
+			params := map[interface{}]interface{}{"length": 32}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate symmetric key, parameters are invalid: Unsupported parameter 'length'"))
+		})
+	})
+})
@@ -0,0 +1,176 @@
+package types
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	DefaultTOTPDigits    = 6
+	DefaultTOTPPeriod    = 30
+	DefaultTOTPAlgorithm = "SHA1"
+
+	totpSeedLength = 20
+)
+
+var totpAlgorithms = map[string]func() hash.Hash{
+	"SHA1":   sha1.New,
+	"SHA256": sha256.New,
+	"SHA512": sha512.New,
+}
+
+type TOTPGenerator struct{}
+
+type TOTPSeed struct {
+	Seed      string `json:"seed" yaml:"seed"`
+	URI       string `json:"uri" yaml:"uri"`
+	Digits    int    `json:"digits" yaml:"digits"`
+	Period    int    `json:"period" yaml:"period"`
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+}
+
+type totpParams struct {
+	Issuer    string `yaml:"issuer"`
+	Account   string `yaml:"account"`
+	Digits    int    `yaml:"digits"`
+	Period    int    `yaml:"period"`
+	Algorithm string `yaml:"algorithm"`
+}
+
+var supportedTOTPParams = []string{
+	"issuer",
+	"account",
+	"digits",
+	"period",
+	"algorithm",
+}
+
+func NewTOTPGenerator() TOTPGenerator {
+	return TOTPGenerator{}
+}
+
+func (g TOTPGenerator) Generate(parameters interface{}) (interface{}, error) {
+	var params totpParams
+	err := objToStruct(parameters, &params, supportedTOTPParams)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate TOTP seed, parameters are invalid")
+	}
+
+	if params.Account == "" {
+		return nil, errors.Error("Failed to generate TOTP seed, 'account' param is required")
+	}
+
+	if params.Digits == 0 {
+		params.Digits = DefaultTOTPDigits
+	}
+
+	if params.Digits != 6 && params.Digits != 8 {
+		return nil, errors.Error("Failed to generate TOTP seed, 'digits' param must be 6 or 8")
+	}
+
+	if params.Period < 0 {
+		return nil, errors.Error("Failed to generate TOTP seed, 'period' param cannot be negative")
+	}
+
+	if params.Period == 0 {
+		params.Period = DefaultTOTPPeriod
+	}
+
+	if params.Algorithm == "" {
+		params.Algorithm = DefaultTOTPAlgorithm
+	}
+
+	params.Algorithm = strings.ToUpper(params.Algorithm)
+	if _, found := totpAlgorithms[params.Algorithm]; !found {
+		return nil, errors.Errorf("Failed to generate TOTP seed, unsupported 'algorithm': %s", params.Algorithm)
+	}
+
+	seedBytes := make([]byte, totpSeedLength)
+	if _, err := rand.Read(seedBytes); err != nil {
+		return nil, errors.WrapError(err, "Generating TOTP seed")
+	}
+
+	seed := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(seedBytes)
+
+	return TOTPSeed{
+		Seed:      seed,
+		URI:       g.provisioningURI(seed, params),
+		Digits:    params.Digits,
+		Period:    params.Period,
+		Algorithm: params.Algorithm,
+	}, nil
+}
+
+// provisioningURI follows the Key Uri Format understood by authenticator apps:
+// https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func (g TOTPGenerator) provisioningURI(seed string, params totpParams) string {
+	label := url.PathEscape(params.Account)
+	if params.Issuer != "" {
+		label = url.PathEscape(params.Issuer) + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", seed)
+	if params.Issuer != "" {
+		query.Set("issuer", params.Issuer)
+	}
+	query.Set("algorithm", params.Algorithm)
+	query.Set("digits", strconv.Itoa(params.Digits))
+	query.Set("period", strconv.Itoa(params.Period))
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// Code computes the RFC 6238 time-based one-time password for the seed at
+// the given time.
+func (s TOTPSeed) Code(at time.Time) (string, error) {
+	newHash, found := totpAlgorithms[strings.ToUpper(s.Algorithm)]
+	if !found {
+		return "", errors.Errorf("Unsupported TOTP algorithm: %s", s.Algorithm)
+	}
+
+	if s.Period <= 0 {
+		return "", errors.Error("TOTP seed must have a positive period")
+	}
+
+	// The truncated HMAC has 31 bits, so codes longer than 10 digits are
+	// meaningless and would overflow the modulo below.
+	if s.Digits < 6 || s.Digits > 10 {
+		return "", errors.Errorf("TOTP seed must have between 6 and 10 digits, got %d", s.Digits)
+	}
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(s.Seed, "=")))
+	if err != nil {
+		return "", errors.WrapError(err, "Decoding TOTP seed")
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(at.Unix())/uint64(s.Period))
+
+	mac := hmac.New(newHash, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < s.Digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", s.Digits, truncated%modulo), nil
+}
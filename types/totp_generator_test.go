@@ -0,0 +1,175 @@
+package types_test
+
+import (
+	"encoding/base32"
+	"fmt"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("TOTPGenerator", func() {
+	var generator ValueGenerator
+
+	BeforeEach(func() {
+		generator = NewTOTPGenerator()
+	})
+
+	Context("Generate", func() {
+		It("generates a base32 seed with default settings", func() {
+			params := map[interface{}]interface{}{"account": "admin"}
+			generated, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			seed := generated.(TOTPSeed)
+			Expect(seed.Seed).To(MatchRegexp("^[A-Z2-7]{32}$"))
+			Expect(seed.Digits).To(Equal(6))
+			Expect(seed.Period).To(Equal(30))
+			Expect(seed.Algorithm).To(Equal("SHA1"))
+
+			decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(seed.Seed)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(HaveLen(20))
+		})
+
+		It("returns an otpauth provisioning URI", func() {
+			params := map[interface{}]interface{}{
+				"issuer":    "BOSH Director",
+				"account":   "admin@example.com",
+				"digits":    8,
+				"period":    60,
+				"algorithm": "sha256",
+			}
+			generated, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			seed := generated.(TOTPSeed)
+			uri, err := url.Parse(seed.URI)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(uri.Scheme).To(Equal("otpauth"))
+			Expect(uri.Host).To(Equal("totp"))
+			Expect(uri.Path).To(Equal("/BOSH Director:admin@example.com"))
+			Expect(uri.Query().Get("secret")).To(Equal(seed.Seed))
+			Expect(uri.Query().Get("issuer")).To(Equal("BOSH Director"))
+			Expect(uri.Query().Get("algorithm")).To(Equal("SHA256"))
+			Expect(uri.Query().Get("digits")).To(Equal("8"))
+			Expect(uri.Query().Get("period")).To(Equal("60"))
+		})
+
+		It("omits the issuer when not provided", func() {
+			params := map[interface{}]interface{}{"account": "admin"}
+			generated, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			uri, _ := url.Parse(generated.(TOTPSeed).URI)
+			Expect(uri.Path).To(Equal("/admin"))
+			Expect(uri.Query()).ToNot(HaveKey("issuer"))
+		})
+
+		It("errors when account is missing", func() {
+			_, err := generator.Generate(nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate TOTP seed, 'account' param is required"))
+		})
+
+		It("errors on unsupported number of digits", func() {
+			params := map[interface{}]interface{}{"account": "admin", "digits": 7}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate TOTP seed, 'digits' param must be 6 or 8"))
+		})
+
+		It("errors on negative period", func() {
+			params := map[interface{}]interface{}{"account": "admin", "period": -30}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate TOTP seed, 'period' param cannot be negative"))
+		})
+
+		It("errors on unsupported algorithms", func() {
+			params := map[interface{}]interface{}{"account": "admin", "algorithm": "MD5"}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate TOTP seed, unsupported 'algorithm': MD5"))
+		})
+
+		It("errors on unknown parameters", func() {
+			params := map[interface{}]interface{}{"account": "admin", "unsupported": 32}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate TOTP seed, parameters are invalid: Unsupported parameter 'unsupported'"))
+		})
+	})
+
+	Context("Code", func() {
+		// Test vectors from RFC 6238 Appendix B
+		rfcSeed := func(secret, algorithm string) TOTPSeed {
+			return TOTPSeed{
+				Seed:      base32.StdEncoding.EncodeToString([]byte(secret)),
+				Digits:    8,
+				Period:    30,
+				Algorithm: algorithm,
+			}
+		}
+
+		It("computes SHA1 codes", func() {
+			seed := rfcSeed("12345678901234567890", "SHA1")
+
+			code, err := seed.Code(time.Unix(59, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal("94287082"))
+
+			code, err = seed.Code(time.Unix(1111111109, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal("07081804"))
+		})
+
+		It("computes SHA256 codes", func() {
+			seed := rfcSeed("12345678901234567890123456789012", "SHA256")
+
+			code, err := seed.Code(time.Unix(1234567890, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal("91819424"))
+		})
+
+		It("computes SHA512 codes", func() {
+			seed := rfcSeed("1234567890123456789012345678901234567890123456789012345678901234", "SHA512")
+
+			code, err := seed.Code(time.Unix(20000000000, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal("47863826"))
+		})
+
+		It("computes 6 digit codes", func() {
+			seed := rfcSeed("12345678901234567890", "SHA1")
+			seed.Digits = 6
+
+			code, err := seed.Code(time.Unix(59, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal("287082"))
+		})
+
+		It("errors when the seed has an unsupported number of digits", func() {
+			for _, digits := range []int{0, 5, 11, 32} {
+				seed := rfcSeed("12345678901234567890", "SHA1")
+				seed.Digits = digits
+
+				_, err := seed.Code(time.Unix(59, 0))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(fmt.Sprintf("TOTP seed must have between 6 and 10 digits, got %d", digits)))
+			}
+		})
+
+		It("errors when the seed is not base32", func() {
+			seed := TOTPSeed{Seed: "not base32!", Digits: 6, Period: 30, Algorithm: "SHA1"}
+			_, err := seed.Code(time.Now())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Decoding TOTP seed"))
+		})
+	})
+})
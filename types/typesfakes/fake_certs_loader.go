@@ -20,6 +20,24 @@ type FakeCertsLoader struct {
 		result2 *rsa.PrivateKey
 		result3 error
 	}
+	LoadChainStub        func(string) (string, error)
+	loadChainMutex       sync.RWMutex
+	loadChainArgsForCall []struct {
+		arg1 string
+	}
+	loadChainReturns struct {
+		result1 string
+		result2 error
+	}
+	LoadCABundleStub        func(string) (string, error)
+	loadCABundleMutex       sync.RWMutex
+	loadCABundleArgsForCall []struct {
+		arg1 string
+	}
+	loadCABundleReturns struct {
+		result1 string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -58,11 +76,81 @@ func (fake *FakeCertsLoader) LoadCertsReturns(result1 *x509.Certificate, result2
 	}{result1, result2, result3}
 }
 
+func (fake *FakeCertsLoader) LoadChain(arg1 string) (string, error) {
+	fake.loadChainMutex.Lock()
+	fake.loadChainArgsForCall = append(fake.loadChainArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("LoadChain", []interface{}{arg1})
+	fake.loadChainMutex.Unlock()
+	if fake.LoadChainStub != nil {
+		return fake.LoadChainStub(arg1)
+	}
+	return fake.loadChainReturns.result1, fake.loadChainReturns.result2
+}
+
+func (fake *FakeCertsLoader) LoadChainCallCount() int {
+	fake.loadChainMutex.RLock()
+	defer fake.loadChainMutex.RUnlock()
+	return len(fake.loadChainArgsForCall)
+}
+
+func (fake *FakeCertsLoader) LoadChainArgsForCall(i int) string {
+	fake.loadChainMutex.RLock()
+	defer fake.loadChainMutex.RUnlock()
+	return fake.loadChainArgsForCall[i].arg1
+}
+
+func (fake *FakeCertsLoader) LoadChainReturns(result1 string, result2 error) {
+	fake.LoadChainStub = nil
+	fake.loadChainReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCertsLoader) LoadCABundle(arg1 string) (string, error) {
+	fake.loadCABundleMutex.Lock()
+	fake.loadCABundleArgsForCall = append(fake.loadCABundleArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("LoadCABundle", []interface{}{arg1})
+	fake.loadCABundleMutex.Unlock()
+	if fake.LoadCABundleStub != nil {
+		return fake.LoadCABundleStub(arg1)
+	}
+	return fake.loadCABundleReturns.result1, fake.loadCABundleReturns.result2
+}
+
+func (fake *FakeCertsLoader) LoadCABundleCallCount() int {
+	fake.loadCABundleMutex.RLock()
+	defer fake.loadCABundleMutex.RUnlock()
+	return len(fake.loadCABundleArgsForCall)
+}
+
+func (fake *FakeCertsLoader) LoadCABundleArgsForCall(i int) string {
+	fake.loadCABundleMutex.RLock()
+	defer fake.loadCABundleMutex.RUnlock()
+	return fake.loadCABundleArgsForCall[i].arg1
+}
+
+func (fake *FakeCertsLoader) LoadCABundleReturns(result1 string, result2 error) {
+	fake.LoadCABundleStub = nil
+	fake.loadCABundleReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCertsLoader) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.loadCertsMutex.RLock()
 	defer fake.loadCertsMutex.RUnlock()
+	fake.loadChainMutex.RLock()
+	defer fake.loadChainMutex.RUnlock()
+	fake.loadCABundleMutex.RLock()
+	defer fake.loadCABundleMutex.RUnlock()
 	return fake.invocations
 }
 
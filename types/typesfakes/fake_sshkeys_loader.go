@@ -0,0 +1,78 @@
+// This file was generated by counterfeiter
+package typesfakes
+
+import (
+	"sync"
+
+	"github.com/shono09835/config-server/types"
+	"golang.org/x/crypto/ssh"
+)
+
+type FakeSSHKeysLoader struct {
+	LoadSSHKeyStub        func(string) (ssh.Signer, error)
+	loadSSHKeyMutex       sync.RWMutex
+	loadSSHKeyArgsForCall []struct {
+		arg1 string
+	}
+	loadSSHKeyReturns struct {
+		result1 ssh.Signer
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSSHKeysLoader) LoadSSHKey(arg1 string) (ssh.Signer, error) {
+	fake.loadSSHKeyMutex.Lock()
+	fake.loadSSHKeyArgsForCall = append(fake.loadSSHKeyArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("LoadSSHKey", []interface{}{arg1})
+	fake.loadSSHKeyMutex.Unlock()
+	if fake.LoadSSHKeyStub != nil {
+		return fake.LoadSSHKeyStub(arg1)
+	}
+	return fake.loadSSHKeyReturns.result1, fake.loadSSHKeyReturns.result2
+}
+
+func (fake *FakeSSHKeysLoader) LoadSSHKeyCallCount() int {
+	fake.loadSSHKeyMutex.RLock()
+	defer fake.loadSSHKeyMutex.RUnlock()
+	return len(fake.loadSSHKeyArgsForCall)
+}
+
+func (fake *FakeSSHKeysLoader) LoadSSHKeyArgsForCall(i int) string {
+	fake.loadSSHKeyMutex.RLock()
+	defer fake.loadSSHKeyMutex.RUnlock()
+	return fake.loadSSHKeyArgsForCall[i].arg1
+}
+
+func (fake *FakeSSHKeysLoader) LoadSSHKeyReturns(result1 ssh.Signer, result2 error) {
+	fake.LoadSSHKeyStub = nil
+	fake.loadSSHKeyReturns = struct {
+		result1 ssh.Signer
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSSHKeysLoader) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.loadSSHKeyMutex.RLock()
+	defer fake.loadSSHKeyMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeSSHKeysLoader) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ types.SSHKeysLoader = new(FakeSSHKeysLoader)
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const DefaultUsernameLength = 20
+
+type UserGenerator struct{}
+
+type UserCredentials struct {
+	Username     string `json:"username" yaml:"username"`
+	Password     string `json:"password" yaml:"password"`
+	PasswordHash string `json:"password_hash" yaml:"password_hash"`
+}
+
+type userParams struct {
+	Username      string `yaml:"username"`
+	Length        int    `yaml:"length"`
+	HashAlgorithm string `yaml:"hash_algorithm"`
+}
+
+var supportedUserParams = []string{
+	"username",
+	"length",
+	"hash_algorithm",
+}
+
+func NewUserGenerator() UserGenerator {
+	return UserGenerator{}
+}
+
+func (g UserGenerator) Generate(parameters interface{}) (interface{}, error) {
+	var params userParams
+	err := objToStruct(parameters, &params, supportedUserParams)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate user, parameters are invalid")
+	}
+
+	if params.Length < 0 {
+		return nil, errors.Error("Failed to generate user, 'length' param cannot be negative")
+	}
+
+	if params.Length == 0 {
+		params.Length = DefaultPasswordLength
+	}
+
+	if params.HashAlgorithm == "" {
+		params.HashAlgorithm = PasswordHashBcrypt
+	}
+
+	if !stringInArray(params.HashAlgorithm, supportedPasswordHashAlgorithms) {
+		return nil, errors.Errorf("Failed to generate user, unsupported 'hash_algorithm': %s", params.HashAlgorithm)
+	}
+
+	username := params.Username
+	if username == "" {
+		username, err = generateRandomString(DefaultUsernameLength)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	password, err := generateRandomString(params.Length)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := hashPassword(password, params.HashAlgorithm)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate user")
+	}
+
+	return UserCredentials{
+		Username:     username,
+		Password:     password,
+		PasswordHash: passwordHash,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package types_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("UserGenerator", func() {
+	var generator ValueGenerator
+
+	BeforeEach(func() {
+		generator = NewUserGenerator()
+	})
+
+	Context("Generate", func() {
+		It("generates a username and a 20 character password", func() {
+			user, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			typedUser := user.(UserCredentials)
+			Expect(typedUser.Username).To(MatchRegexp("^[a-z0-9]{20}$"))
+			Expect(typedUser.Password).To(MatchRegexp("^[a-z0-9]{20}$"))
+		})
+
+		It("uses the provided username", func() {
+			params := map[interface{}]interface{}{"username": "admin"}
+			user, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.(UserCredentials).Username).To(Equal("admin"))
+		})
+
+		It("generates a password of custom length", func() {
+			params := map[interface{}]interface{}{"length": 32}
+			user, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(user.(UserCredentials).Password)).To(Equal(32))
+		})
+
+		It("hashes the password with bcrypt by default", func() {
+			user, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			typedUser := user.(UserCredentials)
+			Expect(typedUser.PasswordHash).To(HavePrefix("$2a$"))
+			Expect(bcrypt.CompareHashAndPassword([]byte(typedUser.PasswordHash), []byte(typedUser.Password))).To(Succeed())
+		})
+
+		It("hashes the password with sha512-crypt", func() {
+			params := map[interface{}]interface{}{"hash_algorithm": "sha512-crypt"}
+			user, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(user.(UserCredentials).PasswordHash).To(MatchRegexp(`^\$6\$[./0-9A-Za-z]{16}\$[./0-9A-Za-z]{86}$`))
+		})
+
+		It("computes sha512-crypt hashes that match the reference implementation", func() {
+			Expect(SHA512Crypt("Hello world!", "saltstring")).To(Equal("$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"))
+		})
+
+		It("hashes the password with pbkdf2-sha256", func() {
+			params := map[interface{}]interface{}{"hash_algorithm": "pbkdf2-sha256"}
+			user, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			typedUser := user.(UserCredentials)
+			parts := strings.Split(typedUser.PasswordHash, "$")
+			Expect(parts).To(HaveLen(5))
+			Expect(parts[1]).To(Equal("pbkdf2-sha256"))
+			Expect(parts[2]).To(Equal("100000"))
+
+			salt, err := base64.RawStdEncoding.DecodeString(strings.ReplaceAll(parts[3], ".", "+"))
+			Expect(err).ToNot(HaveOccurred())
+
+			key := pbkdf2.Key([]byte(typedUser.Password), salt, 100000, 32, sha256.New)
+			Expect(parts[4]).To(Equal(strings.ReplaceAll(base64.RawStdEncoding.EncodeToString(key), "+", ".")))
+		})
+
+		It("errors on unsupported hash algorithms", func() {
+			params := map[interface{}]interface{}{"hash_algorithm": "md5"}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate user, unsupported 'hash_algorithm': md5"))
+		})
+
+		It("errors when the password is too long for bcrypt", func() {
+			params := map[interface{}]interface{}{"length": 73}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate user: Passwords longer than 72 characters cannot be hashed with bcrypt"))
+		})
+
+		It("errors on unknown parameters", func() {
+			params := map[interface{}]interface{}{"unsupported": 32}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate user, parameters are invalid: Unsupported parameter 'unsupported'"))
+		})
+
+		It("errors on negative number for length", func() {
+			params := map[interface{}]interface{}{"length": -1}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate user, 'length' param cannot be negative"))
+		})
+
+		It("serializes nicely in json/yaml", func() {
+			user, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			bytes, err := yaml.Marshal(user)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(bytes)).To(ContainSubstring("username"))
+			Expect(string(bytes)).To(ContainSubstring("password_hash"))
+
+			bytes, err = json.Marshal(user)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(bytes)).To(ContainSubstring("username"))
+			Expect(string(bytes)).To(ContainSubstring("password_hash"))
+		})
+	})
+})
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// Well-known namespaces from RFC 4122 Appendix C
+var uuidNamespaces = map[string]string{
+	"dns":  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	"url":  "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+	"oid":  "6ba7b812-9dad-11d1-80b4-00c04fd430c8",
+	"x500": "6ba7b814-9dad-11d1-80b4-00c04fd430c8",
+}
+
+type UUIDGenerator struct{}
+
+type uuidParams struct {
+	Version   int    `yaml:"version"`
+	Namespace string `yaml:"namespace"`
+	Name      string `yaml:"name"`
+}
+
+var supportedUUIDParams = []string{
+	"version",
+	"namespace",
+	"name",
+}
+
+func NewUUIDGenerator() UUIDGenerator {
+	return UUIDGenerator{}
+}
+
+func (g UUIDGenerator) Generate(parameters interface{}) (interface{}, error) {
+	var params uuidParams
+	err := objToStruct(parameters, &params, supportedUUIDParams)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate UUID, parameters are invalid")
+	}
+
+	switch params.Version {
+	case 0, 4:
+		if params.Namespace != "" || params.Name != "" {
+			return nil, errors.Error("Failed to generate UUID, 'namespace' and 'name' params are only supported for version 5")
+		}
+		return g.generateV4()
+	case 5:
+		if params.Namespace == "" || params.Name == "" {
+			return nil, errors.Error("Failed to generate UUID, 'namespace' and 'name' params are required for version 5")
+		}
+		return g.generateV5(params.Namespace, params.Name)
+	default:
+		return nil, errors.Errorf("Failed to generate UUID, unsupported 'version': %d", params.Version)
+	}
+}
+
+func (g UUIDGenerator) generateV4() (string, error) {
+	uuid := make([]byte, 16)
+	if _, err := rand.Read(uuid); err != nil {
+		return "", errors.WrapError(err, "Generating UUID")
+	}
+
+	return g.format(uuid, 4), nil
+}
+
+func (g UUIDGenerator) generateV5(namespace, name string) (string, error) {
+	if wellKnown, found := uuidNamespaces[strings.ToLower(namespace)]; found {
+		namespace = wellKnown
+	}
+
+	namespaceBytes, err := g.parse(namespace)
+	if err != nil {
+		return "", errors.WrapError(err, "Failed to generate UUID, 'namespace' is invalid")
+	}
+
+	hash := sha1.New()
+	hash.Write(namespaceBytes)
+	hash.Write([]byte(name))
+
+	return g.format(hash.Sum(nil)[:16], 5), nil
+}
+
+func (g UUIDGenerator) parse(uuid string) ([]byte, error) {
+	if len(uuid) != 36 || uuid[8] != '-' || uuid[13] != '-' || uuid[18] != '-' || uuid[23] != '-' {
+		return nil, errors.Errorf("Expected '%s' to be a UUID or one of dns, url, oid, x500", uuid)
+	}
+
+	bytes, err := hex.DecodeString(strings.ReplaceAll(uuid, "-", ""))
+	if err != nil {
+		return nil, errors.Errorf("Expected '%s' to be a UUID or one of dns, url, oid, x500", uuid)
+	}
+
+	return bytes, nil
+}
+
+func (g UUIDGenerator) format(uuid []byte, version byte) string {
+	uuid[6] = (uuid[6] & 0x0f) | version<<4
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
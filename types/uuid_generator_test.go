@@ -0,0 +1,87 @@
+package types_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("UUIDGenerator", func() {
+	var generator ValueGenerator
+
+	BeforeEach(func() {
+		generator = NewUUIDGenerator()
+	})
+
+	Context("Generate", func() {
+		Context("when version is not set", func() {
+			It("generates a random version 4 UUID", func() {
+				uuid, err := generator.Generate(nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(uuid).To(MatchRegexp("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$"))
+			})
+
+			It("generates unique UUIDs", func() {
+				uuid1, err := generator.Generate(nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				uuid2, err := generator.Generate(nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(uuid1).ToNot(Equal(uuid2))
+			})
+
+			It("errors when namespace or name is set", func() {
+				params := map[interface{}]interface{}{"name": "bosh.io"}
+				_, err := generator.Generate(params)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Failed to generate UUID, 'namespace' and 'name' params are only supported for version 5"))
+			})
+		})
+
+		Context("when version is 5", func() {
+			It("generates a name based UUID in a well-known namespace", func() {
+				params := map[interface{}]interface{}{"version": 5, "namespace": "dns", "name": "www.example.com"}
+				uuid, err := generator.Generate(params)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(uuid).To(Equal("2ed6657d-e927-568b-95e1-2665a8aea6a2"))
+			})
+
+			It("generates a name based UUID in a custom namespace", func() {
+				params := map[interface{}]interface{}{"version": 5, "namespace": "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "name": "www.example.com"}
+				uuid, err := generator.Generate(params)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(uuid).To(Equal("2ed6657d-e927-568b-95e1-2665a8aea6a2"))
+			})
+
+			It("errors when name is missing", func() {
+				params := map[interface{}]interface{}{"version": 5, "namespace": "dns"}
+				_, err := generator.Generate(params)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Failed to generate UUID, 'namespace' and 'name' params are required for version 5"))
+			})
+
+			It("errors when namespace is not a UUID", func() {
+				params := map[interface{}]interface{}{"version": 5, "namespace": "smurf", "name": "www.example.com"}
+				_, err := generator.Generate(params)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Failed to generate UUID, 'namespace' is invalid: Expected 'smurf' to be a UUID or one of dns, url, oid, x500"))
+			})
+		})
+
+		It("errors on unsupported versions", func() {
+			params := map[interface{}]interface{}{"version": 1}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate UUID, unsupported 'version': 1"))
+		})
+
+		It("errors on unknown parameters", func() {
+			params := map[interface{}]interface{}{"unsupported": 32}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate UUID, parameters are invalid: Unsupported parameter 'unsupported'"))
+		})
+	})
+})
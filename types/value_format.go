@@ -0,0 +1,287 @@
+package types
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	ValueFormatPEM       = "pem"
+	ValueFormatPKCS8     = "pkcs8"
+	ValueFormatPKCS12    = "pkcs12"
+	ValueFormatDERBase64 = "der-base64"
+	ValueFormatJWK       = "jwk"
+)
+
+// ConvertValueFormat re-encodes the certificates and keys held in a stored
+// value. PEM is the stored encoding; the other formats convert every PEM
+// attribute of the value, except pkcs12 which bundles the certificate, its
+// private key and its issuers into a single base64 encoded archive.
+func ConvertValueFormat(value interface{}, format string, password string) (interface{}, error) {
+	switch format {
+	case "", ValueFormatPEM:
+		return value, nil
+	case ValueFormatPKCS12:
+		return convertToPKCS12(value, password)
+	case ValueFormatPKCS8, ValueFormatDERBase64, ValueFormatJWK:
+	default:
+		return nil, errors.Errorf("Unsupported format '%s'", format)
+	}
+
+	converted := false
+
+	convert := func(attribute interface{}) (interface{}, error) {
+		str, ok := attribute.(string)
+		if !ok {
+			return attribute, nil
+		}
+
+		result, changed, err := convertEncodedString(str, format)
+		converted = converted || changed
+		return result, err
+	}
+
+	var result interface{}
+	var err error
+
+	if valueMap, ok := value.(map[string]interface{}); ok {
+		resultMap := map[string]interface{}{}
+
+		keys := make([]string, 0, len(valueMap))
+		for key := range valueMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			resultMap[key], err = convert(valueMap[key])
+			if err != nil {
+				return nil, errors.WrapErrorf(err, "Converting '%s'", key)
+			}
+		}
+
+		result = resultMap
+	} else {
+		result, err = convert(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !converted {
+		return nil, errors.Errorf("Value cannot be converted to format '%s'", format)
+	}
+
+	return result, nil
+}
+
+// ParsePrivateKeyPEMBlock parses PKCS#1, SEC 1 and PKCS#8 encoded private
+// keys.
+func ParsePrivateKeyPEMBlock(block *pem.Block) (interface{}, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Errorf("Unsupported private key PEM type '%s'", block.Type)
+	}
+}
+
+func isPrivateKeyPEMBlock(block *pem.Block) bool {
+	return block.Type == "RSA PRIVATE KEY" || block.Type == "EC PRIVATE KEY" || block.Type == "PRIVATE KEY"
+}
+
+func decodePEMBlocks(str string) []*pem.Block {
+	var blocks []*pem.Block
+
+	rest := []byte(str)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+
+	if len(strings.TrimSpace(string(rest))) != 0 {
+		return nil
+	}
+
+	return blocks
+}
+
+func convertEncodedString(str string, format string) (interface{}, bool, error) {
+	if format == ValueFormatJWK && strings.HasPrefix(str, "ssh-") {
+		return convertSSHPublicKeyToJWK(str)
+	}
+
+	blocks := decodePEMBlocks(str)
+	if len(blocks) == 0 {
+		return str, false, nil
+	}
+
+	switch format {
+	case ValueFormatPKCS8:
+		if len(blocks) != 1 || !isPrivateKeyPEMBlock(blocks[0]) {
+			return str, false, nil
+		}
+
+		privateKey, err := ParsePrivateKeyPEMBlock(blocks[0])
+		if err != nil {
+			return nil, false, err
+		}
+
+		pkcs8Key, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, false, errors.WrapError(err, "Encoding PKCS#8 private key")
+		}
+
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Key})), true, nil
+	case ValueFormatDERBase64:
+		if len(blocks) == 1 {
+			return base64.StdEncoding.EncodeToString(blocks[0].Bytes), true, nil
+		}
+
+		encoded := make([]string, len(blocks))
+		for i, block := range blocks {
+			encoded[i] = base64.StdEncoding.EncodeToString(block.Bytes)
+		}
+		return encoded, true, nil
+	default:
+		return convertPEMBlocksToJWK(str, blocks)
+	}
+}
+
+func convertPEMBlocksToJWK(str string, blocks []*pem.Block) (interface{}, bool, error) {
+	block := blocks[0]
+
+	switch {
+	case isPrivateKeyPEMBlock(block):
+		privateKey, err := ParsePrivateKeyPEMBlock(block)
+		if err != nil {
+			return nil, false, err
+		}
+
+		jwk, err := NewPrivateJWK(privateKey)
+		return jwk, err == nil, err
+	case block.Type == "PUBLIC KEY" || block.Type == "RSA PUBLIC KEY":
+		var publicKey interface{}
+		var err error
+
+		if block.Type == "PUBLIC KEY" {
+			publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+		} else {
+			publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, false, errors.WrapError(err, "Parsing public key")
+		}
+
+		jwk, err := NewPublicJWK(publicKey)
+		return jwk, err == nil, err
+	case block.Type == "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, false, errors.WrapError(err, "Parsing certificate")
+		}
+
+		jwk, err := NewPublicJWK(certificate.PublicKey)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, certBlock := range blocks {
+			jwk.X5C = append(jwk.X5C, base64.StdEncoding.EncodeToString(certBlock.Bytes))
+		}
+		return jwk, true, nil
+	default:
+		return str, false, nil
+	}
+}
+
+func convertSSHPublicKeyToJWK(str string) (interface{}, bool, error) {
+	sshPublicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(str))
+	if err != nil {
+		return str, false, nil
+	}
+
+	cryptoPublicKey, ok := sshPublicKey.(ssh.CryptoPublicKey)
+	if !ok {
+		return str, false, nil
+	}
+
+	jwk, err := NewPublicJWK(cryptoPublicKey.CryptoPublicKey())
+	if err != nil {
+		return str, false, nil
+	}
+
+	return jwk, true, nil
+}
+
+func convertToPKCS12(value interface{}, password string) (interface{}, error) {
+	if password == "" {
+		return nil, errors.Errorf("Format '%s' requires a password", ValueFormatPKCS12)
+	}
+
+	valueMap, _ := value.(map[string]interface{})
+	certPEM, _ := valueMap["certificate"].(string)
+	keyPEM, _ := valueMap["private_key"].(string)
+
+	if certPEM == "" || keyPEM == "" {
+		return nil, errors.Errorf("Value cannot be converted to format '%s', it must contain a certificate and private_key", ValueFormatPKCS12)
+	}
+
+	certBlock, _ := pem.Decode([]byte(certPEM))
+	if certBlock == nil {
+		return nil, errors.Error("Certificate is not PEM encoded")
+	}
+
+	certificate, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, errors.WrapError(err, "Parsing certificate")
+	}
+
+	keyBlock, _ := pem.Decode([]byte(keyPEM))
+	if keyBlock == nil {
+		return nil, errors.Error("Private key is not PEM encoded")
+	}
+
+	privateKey, err := ParsePrivateKeyPEMBlock(keyBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	issuersPEM, _ := valueMap["chain"].(string)
+	if issuersPEM == "" {
+		issuersPEM, _ = valueMap["ca"].(string)
+	}
+
+	var caCerts []*x509.Certificate
+	for _, block := range decodePEMBlocks(issuersPEM) {
+		caCert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.WrapError(err, "Parsing CA certificate")
+		}
+
+		if !caCert.Equal(certificate) {
+			caCerts = append(caCerts, caCert)
+		}
+	}
+
+	pfx, err := EncodePKCS12(privateKey, certificate, caCerts, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.EncodeToString(pfx), nil
+}
@@ -0,0 +1,220 @@
+package types_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/pkcs12"
+
+	. "github.com/shono09835/config-server/types"
+	"github.com/shono09835/config-server/types/typesfakes"
+)
+
+var _ = Describe("ConvertValueFormat", func() {
+	var (
+		ca       CertResponse
+		leaf     CertResponse
+		caCert   *x509.Certificate
+		leafCert *x509.Certificate
+		value    map[string]interface{}
+	)
+
+	parsePEMCertificate := func(data string) *x509.Certificate {
+		block, _ := pem.Decode([]byte(data))
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		Expect(err).ToNot(HaveOccurred())
+		return certificate
+	}
+
+	BeforeEach(func() {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+
+		template := x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+		caRaw, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+		Expect(err).ToNot(HaveOccurred())
+
+		caCert, _ = x509.ParseCertificate(caRaw)
+		ca = CertResponse{
+			Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caRaw})),
+			PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})),
+		}
+
+		fakeLoader := new(typesfakes.FakeCertsLoader)
+		fakeLoader.LoadCertsReturns(caCert, privateKey, nil)
+		fakeLoader.LoadChainReturns(ca.Certificate, nil)
+		fakeLoader.LoadCABundleReturns(ca.Certificate, nil)
+
+		generated, err := NewCertificateGenerator(fakeLoader).Generate(map[interface{}]interface{}{"ca": "my-ca", "common_name": "leaf"})
+		Expect(err).ToNot(HaveOccurred())
+
+		leaf = generated.(CertResponse)
+		leafCert = parsePEMCertificate(leaf.Certificate)
+
+		value = map[string]interface{}{
+			"certificate": leaf.Certificate,
+			"private_key": leaf.PrivateKey,
+			"ca":          leaf.CA,
+			"chain":       leaf.Chain,
+		}
+	})
+
+	It("returns the value unchanged for pem", func() {
+		converted, err := ConvertValueFormat(value, "pem", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(converted).To(Equal(value))
+	})
+
+	It("errors on unsupported formats", func() {
+		_, err := ConvertValueFormat(value, "xml", "")
+		Expect(err).To(MatchError("Unsupported format 'xml'"))
+	})
+
+	It("errors when the value holds no certificates or keys", func() {
+		_, err := ConvertValueFormat("smurf", "der-base64", "")
+		Expect(err).To(MatchError("Value cannot be converted to format 'der-base64'"))
+	})
+
+	Context("pkcs8", func() {
+		It("re-encodes private keys as PKCS#8 and keeps certificates", func() {
+			converted, err := ConvertValueFormat(value, "pkcs8", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			convertedMap := converted.(map[string]interface{})
+			Expect(convertedMap["certificate"]).To(Equal(leaf.Certificate))
+
+			block, _ := pem.Decode([]byte(convertedMap["private_key"].(string)))
+			Expect(block.Type).To(Equal("PRIVATE KEY"))
+
+			privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(privateKey.(*rsa.PrivateKey).PublicKey.Equal(leafCert.PublicKey)).To(BeTrue())
+		})
+	})
+
+	Context("der-base64", func() {
+		It("encodes every PEM attribute as base64 DER", func() {
+			converted, err := ConvertValueFormat(value, "der-base64", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			convertedMap := converted.(map[string]interface{})
+			Expect(convertedMap["certificate"]).To(Equal(base64.StdEncoding.EncodeToString(leafCert.Raw)))
+			Expect(convertedMap["ca"]).To(Equal(base64.StdEncoding.EncodeToString(caCert.Raw)))
+		})
+
+		It("encodes values with several PEM blocks as a list", func() {
+			converted, err := ConvertValueFormat(ca.Certificate+leaf.Certificate, "der-base64", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(converted).To(Equal([]string{
+				base64.StdEncoding.EncodeToString(caCert.Raw),
+				base64.StdEncoding.EncodeToString(leafCert.Raw),
+			}))
+		})
+	})
+
+	Context("jwk", func() {
+		It("converts certificates and private keys to JWKs", func() {
+			converted, err := ConvertValueFormat(value, "jwk", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			convertedMap := converted.(map[string]interface{})
+
+			certJWK := convertedMap["certificate"].(JWK)
+			Expect(certJWK.KeyType).To(Equal("RSA"))
+			Expect(certJWK.D).To(BeEmpty())
+			Expect(certJWK.X5C).To(Equal([]string{base64.StdEncoding.EncodeToString(leafCert.Raw)}))
+
+			keyJWK := convertedMap["private_key"].(JWK)
+			Expect(keyJWK.N).To(Equal(certJWK.N))
+			Expect(keyJWK.D).ToNot(BeEmpty())
+			Expect(keyJWK.DP).ToNot(BeEmpty())
+			Expect(keyJWK.QI).ToNot(BeEmpty())
+		})
+
+		It("converts EC private keys", func() {
+			privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+
+			der, _ := x509.MarshalECPrivateKey(privateKey)
+			converted, err := ConvertValueFormat(string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), "jwk", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			jwk := converted.(JWK)
+			Expect(jwk.KeyType).To(Equal("EC"))
+			Expect(jwk.Curve).To(Equal("P-256"))
+			Expect(jwk.D).To(HaveLen(43))
+		})
+
+		It("converts SSH public keys", func() {
+			sshKey, err := NewSSHKeyGenerator().Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			converted, err := ConvertValueFormat(map[string]interface{}{"public_key": sshKey.(SSHKey).PublicKey}, "jwk", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(converted.(map[string]interface{})["public_key"].(JWK).KeyType).To(Equal("RSA"))
+		})
+	})
+
+	Context("pkcs12", func() {
+		It("bundles the certificate, key and issuers protected by the password", func() {
+			converted, err := ConvertValueFormat(value, "pkcs12", "s3cret")
+			Expect(err).ToNot(HaveOccurred())
+
+			pfx, err := base64.StdEncoding.DecodeString(converted.(string))
+			Expect(err).ToNot(HaveOccurred())
+
+			blocks, err := pkcs12.ToPEM(pfx, "s3cret")
+			Expect(err).ToNot(HaveOccurred())
+
+			var certificates []*x509.Certificate
+			var privateKey interface{}
+			for _, block := range blocks {
+				if block.Type == "CERTIFICATE" {
+					certificate, err := x509.ParseCertificate(block.Bytes)
+					Expect(err).ToNot(HaveOccurred())
+					certificates = append(certificates, certificate)
+				} else {
+					privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+					Expect(err).ToNot(HaveOccurred())
+				}
+			}
+
+			Expect(certificates).To(HaveLen(2))
+			Expect(certificates[0].Equal(leafCert)).To(BeTrue())
+			Expect(certificates[1].Equal(caCert)).To(BeTrue())
+			Expect(privateKey.(*rsa.PrivateKey).PublicKey.Equal(leafCert.PublicKey)).To(BeTrue())
+		})
+
+		It("cannot be opened with another password", func() {
+			converted, err := ConvertValueFormat(value, "pkcs12", "s3cret")
+			Expect(err).ToNot(HaveOccurred())
+
+			pfx, _ := base64.StdEncoding.DecodeString(converted.(string))
+			_, err = pkcs12.ToPEM(pfx, "wrong")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("requires a password", func() {
+			_, err := ConvertValueFormat(value, "pkcs12", "")
+			Expect(err).To(MatchError("Format 'pkcs12' requires a password"))
+		})
+
+		It("requires a certificate and private key", func() {
+			_, err := ConvertValueFormat(map[string]interface{}{"certificate": leaf.Certificate}, "pkcs12", "s3cret")
+			Expect(err).To(MatchError("Value cannot be converted to format 'pkcs12', it must contain a certificate and private_key"))
+		})
+	})
+})
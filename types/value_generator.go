@@ -5,3 +5,27 @@ package types
 type ValueGenerator interface {
 	Generate(interface{}) (interface{}, error)
 }
+
+// PreviousValueGenerator is implemented by generators that can carry parts
+// of the latest stored version over into a regenerated value. The previous
+// value is passed as stored, i.e. as JSON wrapped in a 'value' key.
+type PreviousValueGenerator interface {
+	GenerateFromPrevious(parameters interface{}, previousValue string) (interface{}, error)
+}
+
+// NamedValueGenerator is implemented by generators that need to know which
+// name a value is generated for.
+type NamedValueGenerator interface {
+	GenerateForName(name string, parameters interface{}) (interface{}, error)
+}
+
+// GenerationInProgressError is returned by generators that gave up waiting
+// for a long running generation. Retrying the request collects the result
+// once it is available.
+type GenerationInProgressError struct {
+	Message string
+}
+
+func (e GenerationInProgressError) Error() string {
+	return e.Message
+}
@@ -1,15 +1,27 @@
 package types
 
 import (
+	"time"
+
 	"github.com/cloudfoundry/bosh-utils/errors"
+
+	"github.com/shono09835/config-server/config"
 )
 
 type ValueGeneratorConcrete struct {
-	loader CertsLoader
+	loader            CertsLoader
+	sshLoader         SSHKeysLoader
+	dhParamsGenerator DHParamsGenerator
+	config            config.GeneratorsConfig
 }
 
-func NewValueGeneratorConcrete(loader CertsLoader) ValueGeneratorConcrete {
-	return ValueGeneratorConcrete{loader}
+func NewValueGeneratorConcrete(loader CertsLoader, sshLoader SSHKeysLoader, config config.GeneratorsConfig) ValueGeneratorConcrete {
+	return ValueGeneratorConcrete{
+		loader:            loader,
+		sshLoader:         sshLoader,
+		dhParamsGenerator: NewDHParamsGenerator(time.Duration(config.DHParamsTimeout) * time.Second),
+		config:            config,
+	}
 }
 
 func (vgc ValueGeneratorConcrete) GetGenerator(valueType string) (ValueGenerator, error) {
@@ -21,8 +33,27 @@ func (vgc ValueGeneratorConcrete) GetGenerator(valueType string) (ValueGenerator
 	case "rsa":
 		return NewRSAKeyGenerator(), nil
 	case "certificate":
-		return NewCertificateGenerator(vgc.loader), nil
+		return NewCertificateGeneratorWithConfig(vgc.loader, vgc.config), nil
+	case "user":
+		return NewUserGenerator(), nil
+	case "symmetric_key":
+		return NewSymmetricKeyGenerator(), nil
+	case "uuid":
+		return NewUUIDGenerator(), nil
+	case "jwt_signing_key":
+		return NewJWTSigningKeyGenerator(), nil
+	case "wireguard":
+		return NewWireGuardKeyGenerator(), nil
+	case "totp":
+		return NewTOTPGenerator(), nil
+	case "dh_params":
+		return vgc.dhParamsGenerator, nil
+	case "ssh_certificate":
+		return NewSSHCertificateGenerator(vgc.sshLoader), nil
 	default:
+		if external, ok := vgc.config.External[valueType]; ok {
+			return NewExternalGenerator(valueType, external), nil
+		}
 		return nil, errors.Errorf("Unsupported value type: %s", valueType)
 	}
 }
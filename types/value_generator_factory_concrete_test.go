@@ -1,6 +1,7 @@
 package types_test
 
 import (
+	"github.com/shono09835/config-server/config"
 	. "github.com/shono09835/config-server/types"
 	"github.com/shono09835/config-server/types/typesfakes"
 
@@ -14,7 +15,7 @@ var _ = Describe("ValueGeneratorFactoryConcrete", func() {
 	Context("GetGenerator", func() {
 		BeforeEach(func() {
 
-			valueGeneratorFactory = NewValueGeneratorConcrete(&typesfakes.FakeCertsLoader{})
+			valueGeneratorFactory = NewValueGeneratorConcrete(&typesfakes.FakeCertsLoader{}, &typesfakes.FakeSSHKeysLoader{}, config.GeneratorsConfig{})
 		})
 
 		It("throws an error for unsupported value types", func() {
@@ -46,5 +47,73 @@ var _ = Describe("ValueGeneratorFactoryConcrete", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(generator).ToNot(BeNil())
 		})
+
+		It("supports the user type", func() {
+			generator, err := valueGeneratorFactory.GetGenerator("user")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).ToNot(BeNil())
+		})
+
+		It("supports the symmetric_key type", func() {
+			generator, err := valueGeneratorFactory.GetGenerator("symmetric_key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).ToNot(BeNil())
+		})
+
+		It("supports the uuid type", func() {
+			generator, err := valueGeneratorFactory.GetGenerator("uuid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).ToNot(BeNil())
+		})
+
+		It("supports the jwt_signing_key type", func() {
+			generator, err := valueGeneratorFactory.GetGenerator("jwt_signing_key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).ToNot(BeNil())
+		})
+
+		It("supports the wireguard type", func() {
+			generator, err := valueGeneratorFactory.GetGenerator("wireguard")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).ToNot(BeNil())
+		})
+
+		It("supports the totp type", func() {
+			generator, err := valueGeneratorFactory.GetGenerator("totp")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).ToNot(BeNil())
+		})
+
+		It("supports the dh_params type", func() {
+			generator, err := valueGeneratorFactory.GetGenerator("dh_params")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).ToNot(BeNil())
+		})
+
+		It("supports the ssh_certificate type", func() {
+			generator, err := valueGeneratorFactory.GetGenerator("ssh_certificate")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).ToNot(BeNil())
+		})
+
+		It("supports external types registered in the config", func() {
+			valueGeneratorFactory = NewValueGeneratorConcrete(&typesfakes.FakeCertsLoader{}, &typesfakes.FakeSSHKeysLoader{}, config.GeneratorsConfig{
+				External: map[string]config.ExternalGeneratorConfig{"vault_token": {Path: "/bin/vault-token"}},
+			})
+
+			generator, err := valueGeneratorFactory.GetGenerator("vault_token")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).To(BeAssignableToTypeOf(ExternalGenerator{}))
+		})
+
+		It("does not let external types replace built-in types", func() {
+			valueGeneratorFactory = NewValueGeneratorConcrete(&typesfakes.FakeCertsLoader{}, &typesfakes.FakeSSHKeysLoader{}, config.GeneratorsConfig{
+				External: map[string]config.ExternalGeneratorConfig{"password": {Path: "/bin/password"}},
+			})
+
+			generator, err := valueGeneratorFactory.GetGenerator("password")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator).ToNot(BeAssignableToTypeOf(ExternalGenerator{}))
+		})
 	})
 })
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/curve25519"
+)
+
+const wireguardKeyLength = 32
+
+type WireGuardKeyGenerator struct{}
+
+type WireGuardKey struct {
+	PrivateKey   string `json:"private_key" yaml:"private_key"`
+	PublicKey    string `json:"public_key" yaml:"public_key"`
+	PresharedKey string `json:"preshared_key,omitempty" yaml:"preshared_key,omitempty"`
+}
+
+type wireguardKeyParams struct {
+	PresharedKey bool `yaml:"preshared_key"`
+}
+
+var supportedWireGuardKeyParams = []string{
+	"preshared_key",
+}
+
+func NewWireGuardKeyGenerator() WireGuardKeyGenerator {
+	return WireGuardKeyGenerator{}
+}
+
+func (g WireGuardKeyGenerator) Generate(parameters interface{}) (interface{}, error) {
+	var params wireguardKeyParams
+	err := objToStruct(parameters, &params, supportedWireGuardKeyParams)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate WireGuard key, parameters are invalid")
+	}
+
+	privateKey := make([]byte, wireguardKeyLength)
+	if _, err := rand.Read(privateKey); err != nil {
+		return nil, errors.WrapError(err, "Generating WireGuard private key")
+	}
+
+	// Clamp the scalar the same way `wg genkey` does
+	privateKey[0] &= 248
+	privateKey[31] = (privateKey[31] & 127) | 64
+
+	publicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, errors.WrapError(err, "Deriving WireGuard public key")
+	}
+
+	key := WireGuardKey{
+		PrivateKey: base64.StdEncoding.EncodeToString(privateKey),
+		PublicKey:  base64.StdEncoding.EncodeToString(publicKey),
+	}
+
+	if params.PresharedKey {
+		presharedKey := make([]byte, wireguardKeyLength)
+		if _, err := rand.Read(presharedKey); err != nil {
+			return nil, errors.WrapError(err, "Generating WireGuard pre-shared key")
+		}
+
+		key.PresharedKey = base64.StdEncoding.EncodeToString(presharedKey)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,87 @@
+package types_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/curve25519"
+
+	. "github.com/shono09835/config-server/types"
+)
+
+var _ = Describe("WireGuardKeyGenerator", func() {
+	var generator ValueGenerator
+
+	BeforeEach(func() {
+		generator = NewWireGuardKeyGenerator()
+	})
+
+	Context("Generate", func() {
+		It("generates a base64 encoded Curve25519 key pair", func() {
+			generated, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			key := generated.(WireGuardKey)
+
+			privateKey, err := base64.StdEncoding.DecodeString(key.PrivateKey)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(privateKey).To(HaveLen(32))
+
+			publicKey, err := base64.StdEncoding.DecodeString(key.PublicKey)
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedPublicKey, err := curve25519.X25519(privateKey, curve25519.Basepoint)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(publicKey).To(Equal(expectedPublicKey))
+		})
+
+		It("clamps the private key", func() {
+			generated, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			privateKey, _ := base64.StdEncoding.DecodeString(generated.(WireGuardKey).PrivateKey)
+			Expect(privateKey[0] & 7).To(Equal(byte(0)))
+			Expect(privateKey[31] & 128).To(Equal(byte(0)))
+			Expect(privateKey[31] & 64).To(Equal(byte(64)))
+		})
+
+		It("does not generate a pre-shared key by default", func() {
+			generated, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generated.(WireGuardKey).PresharedKey).To(BeEmpty())
+
+			bytes, err := json.Marshal(generated)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(bytes)).ToNot(ContainSubstring("preshared_key"))
+		})
+
+		It("generates a pre-shared key when requested", func() {
+			params := map[interface{}]interface{}{"preshared_key": true}
+			generated, err := generator.Generate(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			presharedKey, err := base64.StdEncoding.DecodeString(generated.(WireGuardKey).PresharedKey)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(presharedKey).To(HaveLen(32))
+		})
+
+		It("generates unique keys", func() {
+			key1, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			key2, err := generator.Generate(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(key1.(WireGuardKey).PrivateKey).ToNot(Equal(key2.(WireGuardKey).PrivateKey))
+		})
+
+		It("errors on unknown parameters", func() {
+			params := map[interface{}]interface{}{"unsupported": 32}
+			_, err := generator.Generate(params)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Failed to generate WireGuard key, parameters are invalid: Unsupported parameter 'unsupported'"))
+		})
+	})
+})